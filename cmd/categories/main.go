@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"wb-parser/internal/api"
+	"wb-parser/internal/cli"
+	"wb-parser/internal/model"
+	"wb-parser/internal/service"
+)
+
+var (
+	marketplace string
+	depth       int
+	output      string
+	format      string
+	enqueue     string
+	pages       int
+	block       *cli.BlockFlags
+	retries     *cli.RetryFlags
+	rates       *cli.RateFlags
+	profile     *cli.ProfileFlags
+)
+
+func init() {
+	flag.StringVar(&marketplace, "marketplace", "wb", "Marketplace: wb, ozon, ali")
+	flag.IntVar(&depth, "depth", 2, "Levels of the tree to crawl, 0 for all")
+	flag.StringVar(&output, "output", "output", "Output path")
+	flag.StringVar(&format, "format", "csv", "Output format: csv, json")
+	flag.StringVar(&enqueue, "enqueue", "", "HTTP API server address to submit a crawl of every leaf category to, e.g. localhost:8080 (optional)")
+	flag.IntVar(&pages, "pages", 30, "Max pages of the enqueued crawls")
+	block = cli.RegisterBlockFlags(flag.CommandLine)
+	retries = cli.RegisterRetryFlags(flag.CommandLine)
+	rates = cli.RegisterRateFlags(flag.CommandLine)
+	profile = cli.RegisterProfileFlags(flag.CommandLine)
+}
+
+func main() {
+	flag.Parse()
+
+	if format != "csv" && format != "json" {
+		fmt.Printf("unknown format %q\n", format)
+		return
+	}
+	blockOpt, err := block.Option()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	rateLimits, err := rates.Option(marketplace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	opts := []service.Option{blockOpt, retries.Option(), rateLimits}
+	profileOpts, err := profile.Options()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	opts = append(opts, profileOpts...)
+
+	s, err := service.NewCatalogService(marketplace, opts...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	start := time.Now()
+	// categories found before an error are still written
+	categories, err := s.Categories(context.TODO(), depth)
+	if err != nil {
+		fmt.Println(err)
+	}
+	if len(categories) == 0 {
+		return
+	}
+	if err := writeCategories(categories); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if enqueue != "" {
+		client := api.NewClient(enqueue)
+		submitted := 0
+		for _, c := range categories {
+			if !c.Leaf {
+				continue
+			}
+			job, err := client.Submit(context.TODO(), api.JobRequest{Marketplace: marketplace, Url: c.Url, Pages: pages})
+			if err != nil {
+				fmt.Println(c.Url, err)
+				continue
+			}
+			fmt.Println("job", job.ID, c.Name, c.Url)
+			submitted++
+		}
+		fmt.Println("Submitted jobs:", submitted)
+	}
+
+	fmt.Println(len(categories), "categories", time.Since(start).Seconds())
+}
+
+func writeCategories(categories []*model.Category) error {
+	if err := os.MkdirAll(output, os.ModePerm); err != nil {
+		return err
+	}
+	filepath := fmt.Sprintf("%s/%s-categories-%s.%s", output, marketplace, time.Now().Format("2006-01-02_15-04-05"), format)
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if format == "json" {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(categories)
+	}
+
+	csvw := csv.NewWriter(f)
+	if err := csvw.Write([]string{"id", "parent_id", "name", "url", "depth", "leaf"}); err != nil {
+		return err
+	}
+	for _, c := range categories {
+		if err := csvw.Write([]string{
+			c.ID,
+			c.ParentID,
+			c.Name,
+			c.Url,
+			strconv.Itoa(c.Depth),
+			strconv.FormatBool(c.Leaf),
+		}); err != nil {
+			return err
+		}
+	}
+	csvw.Flush()
+	return csvw.Error()
+}
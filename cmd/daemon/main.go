@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"wb-parser/internal/daemon"
+	"wb-parser/internal/metrics"
+)
+
+var (
+	config      string
+	metricsAddr string
+)
+
+func init() {
+	flag.StringVar(&config, "config", "daemon.json", "Jobs config")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Address to expose Prometheus /metrics, e.g. :9100 (optional)")
+}
+
+func main() {
+	flag.Parse()
+
+	if metricsAddr != "" {
+		metrics.Serve(metricsAddr)
+	}
+
+	cfg, err := daemon.LoadConfig(config)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	d, err := daemon.New(cfg)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := d.Run(ctx); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"wb-parser/internal/diff"
+	"wb-parser/internal/model"
+	"wb-parser/internal/storage"
+)
+
+var (
+	oldPath   string
+	newPath   string
+	db        string
+	oldRun    int64
+	newRun    int64
+	threshold float64
+	format    string
+	output    string
+)
+
+func init() {
+	flag.StringVar(&oldPath, "old", "", "Previous result csv file")
+	flag.StringVar(&newPath, "new", "", "Current result csv file")
+	flag.StringVar(&db, "db", "", "SQLite database path to compare runs instead of files")
+	flag.Int64Var(&oldRun, "old-run", 0, "Previous run id (with -db)")
+	flag.Int64Var(&newRun, "new-run", 0, "Current run id (with -db)")
+	flag.Float64Var(&threshold, "threshold", 5, "Min price change in percent")
+	flag.StringVar(&format, "format", diff.FormatCSV, "Report format: csv, json, markdown")
+	flag.StringVar(&output, "output", "", "Report path, stdout if empty")
+}
+
+func main() {
+	flag.Parse()
+
+	switch format {
+	case diff.FormatCSV, diff.FormatJSON, diff.FormatMarkdown:
+	default:
+		fmt.Printf("unknown format %q\n", format)
+		return
+	}
+
+	before, after, err := load(context.TODO())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	changes := diff.Compare(before, after, diff.Options{PriceThreshold: threshold})
+
+	w := os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := diff.Write(w, format, changes); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func load(ctx context.Context) ([]*model.ProductCard, []*model.ProductCard, error) {
+	if db == "" {
+		before, err := storage.ReadCSV(oldPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := storage.ReadCSV(newPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return before, after, nil
+	}
+
+	st, err := storage.NewSQLiteStorage(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer st.Close()
+	before, err := st.RunProducts(ctx, oldRun)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := st.RunProducts(ctx, newRun)
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
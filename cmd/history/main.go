@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"wb-parser/internal/storage"
+)
+
+var (
+	db          string
+	marketplace string
+	productID   string
+	runs        bool
+)
+
+func init() {
+	flag.StringVar(&db, "db", "output/products.db", "SQLite database path")
+	flag.StringVar(&marketplace, "marketplace", "wb", "Marketplace: wb, ozon, ali")
+	flag.StringVar(&productID, "id", "", "Product id")
+	flag.BoolVar(&runs, "runs", false, "List stored runs instead of product history")
+}
+
+func main() {
+	flag.Parse()
+
+	st, err := storage.NewSQLiteStorage(db)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer st.Close()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	if runs {
+		list, err := st.Runs(context.TODO())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Fprintln(tw, "run\tdate\tmarketplace\tregion\tproducts\turl")
+		for _, r := range list {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%s\n",
+				r.ID, r.StartedAt.Local().Format("2006-01-02 15:04"), r.Marketplace, orDash(r.Region), r.Products, r.Url)
+		}
+		return
+	}
+
+	observations, err := st.PriceHistory(context.TODO(), marketplace, productID)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Fprintln(tw, "run\tregion\tdate\tprice\tfull_price\trate\treviews\tposition")
+	for _, o := range observations {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			o.RunID,
+			orDash(o.Region),
+			o.ObservedAt.Local().Format("2006-01-02 15:04"),
+			formatFloat(o.Price.Float64, o.Price.Valid),
+			formatFloat(o.FullPrice.Float64, o.FullPrice.Valid),
+			formatFloat(o.Rate.Float64, o.Rate.Valid),
+			formatFloat(float64(o.Reviews.Int64), o.Reviews.Valid),
+			o.Position,
+		)
+	}
+}
+
+func formatFloat(value float64, valid bool) string {
+	if !valid {
+		return "-"
+	}
+	return fmt.Sprint(value)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -2,31 +2,141 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"time"
+	"wb-parser/internal/alert"
+	"wb-parser/internal/cli"
+	"wb-parser/internal/metrics"
+	"wb-parser/internal/regions"
 	"wb-parser/internal/service"
+	"wb-parser/internal/storage"
 )
 
 var (
 	categoryUrl string
 	pages       int
 	output      string
+	db          string
+	alerts      string
+	search      *cli.SearchFlags
+	block       *cli.BlockFlags
+	retries     *cli.RetryFlags
+	lazy        *cli.LazyFlags
+	rates       *cli.RateFlags
+	metricsAddr string
+	region      *cli.RegionFlags
+	profile     *cli.ProfileFlags
+	facets      *cli.FacetFlags
+	sliceLimit  int
 )
 
 func init() {
 	flag.StringVar(&categoryUrl, "url", "https://www.ozon.ru/category/shvabry-14618/?text=%D1%88%D0%B2%D0%B0%D0%B1%D1%80%D0%B0", "Category url")
 	flag.IntVar(&pages, "pages", 30, "Max Pages")
 	flag.StringVar(&output, "output", "output", "Output path")
+	flag.StringVar(&db, "db", "", "SQLite database path to keep price history (optional)")
+	flag.StringVar(&alerts, "alerts", "", "Alert rules and notifiers config, requires -db (optional)")
+	search = cli.RegisterSearchFlags(flag.CommandLine)
+	block = cli.RegisterBlockFlags(flag.CommandLine)
+	retries = cli.RegisterRetryFlags(flag.CommandLine)
+	lazy = cli.RegisterLazyFlags(flag.CommandLine)
+	rates = cli.RegisterRateFlags(flag.CommandLine)
+	region = cli.RegisterRegionFlags(flag.CommandLine, "Delivery city, e.g. Казань")
+	profile = cli.RegisterProfileFlags(flag.CommandLine)
+	facets = cli.RegisterFacetFlags(flag.CommandLine)
+	flag.IntVar(&sliceLimit, "slice-limit", 0, "Split the catalog into price ranges of at most N products to get past the page depth limit, 0 to disable")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Address to expose Prometheus /metrics, e.g. :9100 (optional)")
 }
 
 func main() {
 	flag.Parse()
 
-	s := service.NewOzonCatalogService()
+	if metricsAddr != "" {
+		metrics.Serve(metricsAddr)
+	}
+
+	if alerts != "" && db == "" {
+		fmt.Println("-alerts requires -db")
+		return
+	}
+	if facets.Split() != "" && sliceLimit > 0 {
+		fmt.Println("-split-facet can not be combined with -slice-limit")
+		return
+	}
+
+	blockOpt, err := block.Option()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	rateLimits, err := rates.Option(service.MarketplaceOzon)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	opts := []service.Option{blockOpt, retries.Option(), lazy.Option(), rateLimits, region.Option(), facets.Option(), service.WithPriceSlicing(sliceLimit)}
+	profileOpts, err := profile.Options()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	opts = append(opts, profileOpts...)
+	if db != "" {
+		st, err := storage.NewSQLiteStorage(db)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer st.Close()
+
+		var sink service.Sink = st
+		if alerts != "" {
+			cfg, err := alert.LoadConfig(alerts)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			sink = alert.NewEngine(st, cfg.Rules, cfg.Notifiers())
+		}
+		opts = append(opts, service.WithSink(sink))
+	}
+
+	s := service.NewOzonCatalogService(opts...)
+
+	queries, err := search.Queries(service.MarketplaceOzon)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	parse := func(url string, output string) error {
+		if facets.List() {
+			list, err := s.Facets(context.TODO(), url)
+			if err != nil {
+				return err
+			}
+			return cli.WriteFacets(output, service.MarketplaceOzon, list)
+		}
+		if len(region.Regions()) == 0 {
+			return s.Parse(context.TODO(), url, pages, output)
+		}
+		results, err := regions.Collect(context.TODO(), service.MarketplaceOzon, region.Regions(), url, pages, output, opts)
+		return errors.Join(err, regions.WriteResults(output, service.MarketplaceOzon, results))
+	}
 
 	start := time.Now()
-	s.Parse(context.TODO(), categoryUrl, pages, output)
+	if len(queries) == 0 {
+		if err := parse(categoryUrl, output); err != nil {
+			fmt.Println(err)
+		}
+	}
+	for _, q := range queries {
+		if err := parse(s.SearchURL(q), cli.QueryOutput(output, q)); err != nil {
+			fmt.Println(err)
+		}
+	}
 
 	fmt.Println(time.Since(start).Seconds())
 
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wb-parser/internal/api"
+	"wb-parser/internal/metrics"
+	"wb-parser/internal/service"
+	"wb-parser/internal/storage"
+	"wb-parser/package/ratelimit"
+)
+
+var (
+	addr      string
+	workers   int
+	queueSize int
+	output    string
+	db        string
+	limits    string
+	quotaFile string
+	jobTTL    time.Duration
+	maxJobs   int
+)
+
+func init() {
+	flag.StringVar(&addr, "addr", ":8080", "Listen address")
+	flag.IntVar(&workers, "workers", 1, "Parallel crawls")
+	flag.IntVar(&queueSize, "queue", 100, "Max queued jobs")
+	flag.StringVar(&output, "output", "output", "Output path")
+	flag.StringVar(&db, "db", "", "SQLite database path to keep price history (optional)")
+	flag.StringVar(&limits, "rate-limits", "", "JSON file with per-marketplace rate limits shared by workers (optional)")
+	flag.StringVar(&quotaFile, "quota-file", "output/quota.json", "JSON file keeping daily quota counts between restarts, in memory only if empty")
+	flag.DurationVar(&jobTTL, "job-ttl", 24*time.Hour, "Time to keep finished jobs and their products in memory, 0 to keep them forever")
+	flag.IntVar(&maxJobs, "max-jobs", 1000, "Max finished jobs kept in memory, 0 is unlimited")
+}
+
+func main() {
+	flag.Parse()
+
+	opts := []service.Option{}
+	if limits != "" {
+		registry, err := ratelimit.LoadRegistry(limits)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if quotaFile != "" {
+			registry.PersistQuota(quotaFile)
+		}
+		opts = append(opts, service.WithRateLimits(registry))
+	}
+	if db != "" {
+		st, err := storage.NewSQLiteStorage(db)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer st.Close()
+		opts = append(opts, service.WithSink(st))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	queue := api.NewQueue(queueSize, api.Retention{TTL: jobTTL, Max: maxJobs}, output, opts...)
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx, workers)
+		close(done)
+	}()
+
+	handler := api.NewServer(queue)
+	handler.Handle("/metrics", metrics.Handler())
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	fmt.Println("listening on", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Println(err)
+	}
+	<-done
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"wb-parser/internal/cli"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/chromedp"
+)
+
+var (
+	pageUrl string
+	profile *cli.ProfileFlags
+)
+
+func init() {
+	flag.StringVar(&pageUrl, "url", "https://www.wildberries.ru/", "Page to open, e.g. the marketplace login page")
+	profile = cli.RegisterProfileFlags(flag.CommandLine)
+}
+
+// session opens Chrome with the profile and imported cookies to log in or
+// choose settings by hand, then keeps the profile and exports the cookies.
+func main() {
+	flag.Parse()
+
+	dir, err := profile.ProfileDir()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	importFile, exportFile := profile.CookieFiles()
+	if dir == "" && exportFile == "" {
+		fmt.Println("-profile or -cookies-export is required to keep the session")
+		return
+	}
+
+	opts := []chromedp.ExecAllocatorOption{}
+	if dir != "" {
+		opts = append(opts, chromedputils.WithProfile(dir))
+	}
+	ctx, cancel := chromedputils.InitChromeDPContext(context.Background(), opts...)
+	defer cancel()
+
+	if importFile != "" {
+		cookies, err := chromedputils.LoadCookies(importFile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := chromedputils.SetCookies(ctx, cookies); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if err := chromedp.Run(ctx, chromedp.Navigate(pageUrl)); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println("Log in or change settings in the browser window, then press Enter")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+
+	if exportFile != "" {
+		cookies, err := chromedputils.GetCookies(ctx)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := chromedputils.SaveCookies(exportFile, cookies); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(len(cookies), "cookies saved to", exportFile)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+	"wb-parser/internal/metrics"
+	"wb-parser/internal/tracker"
+)
+
+var (
+	config      string
+	history     string
+	pages       int
+	interval    time.Duration
+	report      bool
+	metricsAddr string
+)
+
+func init() {
+	flag.StringVar(&config, "config", "tracker.json", "Tracked keywords and products")
+	flag.StringVar(&history, "history", "output/positions.jsonl", "Positions history path")
+	flag.IntVar(&pages, "pages", 5, "Max Pages")
+	flag.DurationVar(&interval, "interval", 0, "Run periodically with the interval, run once if 0")
+	flag.BoolVar(&report, "report", false, "Print rank change report and exit")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Address to expose Prometheus /metrics, e.g. :9100 (optional)")
+}
+
+func main() {
+	flag.Parse()
+
+	if metricsAddr != "" {
+		metrics.Serve(metricsAddr)
+	}
+
+	h := tracker.NewHistory(history)
+
+	if report {
+		records, err := h.Load()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := tracker.WriteReport(os.Stdout, tracker.BuildReport(records)); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	targets, err := tracker.LoadTargets(config)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := tracker.NewTracker(targets, h, pages)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for {
+		start := time.Now()
+		if err := t.Run(ctx); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(time.Since(start).Seconds())
+
+		if interval == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
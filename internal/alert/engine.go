@@ -0,0 +1,92 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"wb-parser/internal/model"
+	"wb-parser/internal/storage"
+)
+
+type Config struct {
+	Rules    []*Rule           `json:"rules"`
+	Webhook  *WebhookNotifier  `json:"webhook"`
+	Email    *EmailNotifier    `json:"email"`
+	Telegram *TelegramNotifier `json:"telegram"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	for _, rule := range cfg.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func (c *Config) Notifiers() []Notifier {
+	notifiers := []Notifier{}
+	if c.Webhook != nil {
+		notifiers = append(notifiers, c.Webhook)
+	}
+	if c.Email != nil {
+		notifiers = append(notifiers, c.Email)
+	}
+	if c.Telegram != nil {
+		notifiers = append(notifiers, c.Telegram)
+	}
+	return notifiers
+}
+
+// Engine stores products of every run and evaluates alert rules against the
+// previous run of the same url and region. It is used as a service sink instead of the
+// storage itself.
+type Engine struct {
+	storage   *storage.SQLiteStorage
+	rules     []*Rule
+	notifiers []Notifier
+}
+
+func NewEngine(st *storage.SQLiteStorage, rules []*Rule, notifiers []Notifier) *Engine {
+	return &Engine{storage: st, rules: rules, notifiers: notifiers}
+}
+
+func (e *Engine) Save(ctx context.Context, marketplace string, url string, region string, products []*model.ProductCard) error {
+	var previous []*model.ProductCard
+	previousRun, err := e.storage.LastRun(ctx, marketplace, url, region)
+	if err != nil {
+		return err
+	}
+	if previousRun != 0 {
+		if previous, err = e.storage.RunProducts(ctx, previousRun); err != nil {
+			return err
+		}
+	}
+
+	if err := e.storage.Save(ctx, marketplace, url, region, products); err != nil {
+		return err
+	}
+
+	alerts := []*Alert{}
+	for _, rule := range e.rules {
+		alerts = append(alerts, rule.Evaluate(marketplace, previous, products)...)
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+	for _, n := range e.notifiers {
+		if err := n.Notify(ctx, alerts); err != nil {
+			fmt.Println(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, alerts []*Alert) error
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// WebhookNotifier posts alerts as a JSON array to the url.
+type WebhookNotifier struct {
+	URL string `json:"url"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alerts []*Alert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	return post(ctx, n.URL, body)
+}
+
+// EmailNotifier sends alerts with a single email via SMTP server.
+type EmailNotifier struct {
+	Addr     string   `json:"addr"` // host:port
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, alerts []*Alert) error {
+	host, _, err := net.SplitHostPort(n.Addr)
+	if err != nil {
+		return err
+	}
+
+	msg := &bytes.Buffer{}
+	fmt.Fprintf(msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(msg, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(msg, "Subject: ec-parser: %d alerts\r\n", len(alerts))
+	fmt.Fprintf(msg, "Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(text(alerts))
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	// net/smtp has no context support, closing the connection aborts the dialog
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	if err := n.send(conn, host, msg.Bytes()); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// send is smtp.SendMail over the connection.
+func (n *EmailNotifier) send(conn net.Conn, host string, msg []byte) error {
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// TelegramNotifier sends alerts to the chat via Telegram bot API.
+type TelegramNotifier struct {
+	Token  string `json:"token"`
+	ChatID string `json:"chat_id"`
+	// APIURL allows to use a local bot API server, https://api.telegram.org by default.
+	APIURL string `json:"api_url"`
+}
+
+func (n *TelegramNotifier) Notify(ctx context.Context, alerts []*Alert) error {
+	apiURL := n.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.telegram.org"
+	}
+	body, err := json.Marshal(map[string]string{
+		"chat_id": n.ChatID,
+		"text":    text(alerts),
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(apiURL, "/"), n.Token), body)
+}
+
+func post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notify %s: unexpected status %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+func text(alerts []*Alert) string {
+	lines := []string{}
+	for _, a := range alerts {
+		lines = append(lines, fmt.Sprintf("[%s] %s", a.Marketplace, a.Message))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,192 @@
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wb-parser/internal/model"
+)
+
+func testAlerts() []*Alert {
+	return []*Alert{{
+		Rule:        &Rule{Type: RuleNewProduct},
+		Marketplace: "wb",
+		Product:     &model.ProductCard{ID: "1", Url: "https://www.wildberries.ru/catalog/1/detail.aspx"},
+		Message:     "New product: Чайник https://www.wildberries.ru/catalog/1/detail.aspx",
+	}}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got []*Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("content type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	n := &WebhookNotifier{URL: srv.URL}
+	if err := n.Notify(context.Background(), testAlerts()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Message != testAlerts()[0].Message || got[0].Product.ID != "1" {
+		t.Errorf("received %+v", got)
+	}
+}
+
+func TestWebhookNotifierStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	n := &WebhookNotifier{URL: srv.URL}
+	if err := n.Notify(context.Background(), testAlerts()); err == nil {
+		t.Fatal("expected an error for 502 response")
+	}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	var path string
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	n := &TelegramNotifier{Token: "123:abc", ChatID: "-100", APIURL: srv.URL + "/"}
+	if err := n.Notify(context.Background(), testAlerts()); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %q", path)
+	}
+	if got["chat_id"] != "-100" {
+		t.Errorf("chat_id = %q", got["chat_id"])
+	}
+	if want := "[wb] " + testAlerts()[0].Message; got["text"] != want {
+		t.Errorf("text = %q, want %q", got["text"], want)
+	}
+}
+
+// fakeSMTP accepts a single connection and answers the commands of
+// net/smtp, the received DATA is sent to the channel.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				msg := &strings.Builder{}
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					msg.WriteString(line)
+				}
+				data <- msg.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return l.Addr().String(), data
+}
+
+func TestEmailNotifier(t *testing.T) {
+	addr, data := fakeSMTP(t)
+	n := &EmailNotifier{Addr: addr, From: "parser@example.com", To: []string{"a@example.com", "b@example.com"}}
+	if err := n.Notify(context.Background(), testAlerts()); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-data
+	for _, want := range []string{
+		"From: parser@example.com",
+		"To: a@example.com, b@example.com",
+		"Subject: ec-parser: 1 alerts",
+		"[wb] New product: Чайник",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message has no %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestEmailNotifierContext(t *testing.T) {
+	// the server accepts the connection but never greets
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	n := &EmailNotifier{Addr: l.Addr().String(), From: "parser@example.com", To: []string{"a@example.com"}}
+	start := time.Now()
+	err = n.Notify(ctx, testAlerts())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Notify returned after %v", elapsed)
+	}
+}
@@ -0,0 +1,94 @@
+package alert
+
+import (
+	"fmt"
+	"wb-parser/internal/diff"
+	"wb-parser/internal/model"
+)
+
+const (
+	RulePriceDrop   = "price_drop"
+	RuleNewProduct  = "new_product"
+	RuleRatingBelow = "rating_below"
+)
+
+// Rule is a condition checked for every product after a run.
+//
+//	{"type": "price_drop", "value": 10}   price fell by 10% or more
+//	{"type": "new_product"}               product appeared in the listing
+//	{"type": "rating_below", "value": 4}  rating fell below 4.0
+type Rule struct {
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+type Alert struct {
+	Rule        *Rule              `json:"rule"`
+	Marketplace string             `json:"marketplace"`
+	Product     *model.ProductCard `json:"product"`
+	Message     string             `json:"message"`
+}
+
+func (r *Rule) Validate() error {
+	switch r.Type {
+	case RulePriceDrop, RuleNewProduct, RuleRatingBelow:
+		return nil
+	}
+	return fmt.Errorf("unknown alert rule %q", r.Type)
+}
+
+// Evaluate compares the products with the previous run. Nothing is reported
+// for the first run of the url or when either run is empty, e.g. blocked,
+// otherwise every product would be new.
+func (r *Rule) Evaluate(marketplace string, previous []*model.ProductCard, products []*model.ProductCard) []*Alert {
+	if len(previous) == 0 || len(products) == 0 {
+		return nil
+	}
+	current := map[string]*model.ProductCard{}
+	for _, product := range products {
+		current[product.Key()] = product
+	}
+
+	alerts := []*Alert{}
+	add := func(product *model.ProductCard, message string) {
+		alerts = append(alerts, &Alert{Rule: r, Marketplace: marketplace, Product: product, Message: message})
+	}
+
+	switch r.Type {
+	case RulePriceDrop:
+		for _, c := range diff.Compare(previous, products, diff.Options{PriceThreshold: r.Value}) {
+			if c.Kind == diff.KindPrice && *c.PriceChange <= -r.Value {
+				add(current[(&model.ProductCard{ID: c.ID, Url: c.Url}).Key()], fmt.Sprintf(
+					"Price drop %.1f%%: %s (%v → %v) %s", -*c.PriceChange, c.Title, *c.OldPrice, *c.NewPrice, c.Url,
+				))
+			}
+		}
+	case RuleNewProduct:
+		for _, c := range diff.Compare(previous, products, diff.Options{}) {
+			if c.Kind == diff.KindNew {
+				add(current[(&model.ProductCard{ID: c.ID, Url: c.Url}).Key()], fmt.Sprintf(
+					"New product: %s %s", c.Title, c.Url,
+				))
+			}
+		}
+	case RuleRatingBelow:
+		before := map[string]*model.ProductCard{}
+		for _, product := range previous {
+			before[product.Key()] = product
+		}
+		for _, product := range products {
+			rate, ok := model.Number(product.Rate)
+			if !ok || rate >= r.Value {
+				continue
+			}
+			// report only when the rating crosses the limit
+			if prev, ok := before[product.Key()]; ok {
+				if prevRate, ok := model.Number(prev.Rate); ok && prevRate < r.Value {
+					continue
+				}
+			}
+			add(product, fmt.Sprintf("Rating %v is below %v: %s %s", rate, r.Value, product.Title, product.Url))
+		}
+	}
+	return alerts
+}
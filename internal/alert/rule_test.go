@@ -0,0 +1,72 @@
+package alert
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"wb-parser/internal/model"
+)
+
+func alerted(alerts []*Alert) string {
+	ids := []string{}
+	for _, a := range alerts {
+		ids = append(ids, a.Product.ID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func TestEvaluate(t *testing.T) {
+	previous := []*model.ProductCard{
+		{ID: "1", Url: "u1", Price: "100", Rate: "4.5"},
+		{ID: "2", Url: "u2", Price: "100", Rate: "4.5"},
+		{ID: "3", Url: "u3", Price: "100", Rate: "3.5"},
+		{ID: "4", Url: "u4", Price: "100", Rate: "4.5"},
+		{ID: "5", Url: "u5", Price: "100", Rate: "4.5"},
+	}
+	products := []*model.ProductCard{
+		{ID: "1", Url: "u1", Price: "85", Rate: "3.9"},  // price drop, rating crossed the limit
+		{ID: "2", Url: "u2", Price: "95", Rate: "4.0"},  // small drop, rating at the limit
+		{ID: "3", Url: "u3", Price: "120", Rate: "3.0"}, // price rise, rating was already below
+		{ID: "4", Url: "u4", Price: "90", Rate: "4.5"},  // drop exactly at the threshold
+		{ID: "5", Url: "u5", Price: "100", Rate: ""},    // no rating
+		{ID: "6", Url: "u6", Price: "50", Rate: "3.0"},  // new product below the limit
+	}
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{Type: RulePriceDrop, Value: 10}, "1,4"},
+		{Rule{Type: RuleNewProduct}, "6"},
+		{Rule{Type: RuleRatingBelow, Value: 4}, "1,6"},
+	}
+	for _, tt := range tests {
+		if got := alerted(tt.rule.Evaluate("wb", previous, products)); got != tt.want {
+			t.Errorf("%s %v: alerted %q, want %q", tt.rule.Type, tt.rule.Value, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateEmptyRun(t *testing.T) {
+	products := []*model.ProductCard{{ID: "1", Url: "u1", Price: "100", Rate: "3.0"}}
+	rules := []Rule{{Type: RulePriceDrop, Value: 10}, {Type: RuleNewProduct}, {Type: RuleRatingBelow, Value: 4}}
+	for _, rule := range rules {
+		// the first run of the url
+		if alerts := rule.Evaluate("wb", nil, products); len(alerts) != 0 {
+			t.Errorf("%s: %d alerts for the first run", rule.Type, len(alerts))
+		}
+		// an empty run, e.g. blocked
+		if alerts := rule.Evaluate("wb", products, nil); len(alerts) != 0 {
+			t.Errorf("%s: %d alerts for an empty run", rule.Type, len(alerts))
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (&Rule{Type: "price_rise"}).Validate(); err == nil {
+		t.Error("unknown rule type is accepted")
+	}
+	if err := (&Rule{Type: RulePriceDrop, Value: 5}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Client submits jobs to the Server from other commands.
+type Client struct {
+	addr string
+	http *http.Client
+}
+
+// NewClient returns a client of the server at addr, e.g. localhost:8080.
+func NewClient(addr string) *Client {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return &Client{addr: strings.TrimRight(addr, "/"), http: http.DefaultClient}
+}
+
+func (c *Client) Submit(ctx context.Context, req JobRequest) (*JobInfo, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+"/jobs", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		res := map[string]string{}
+		json.NewDecoder(resp.Body).Decode(&res)
+		return nil, fmt.Errorf("submit job: %s: %s", resp.Status, res["error"])
+	}
+	info := &JobInfo{}
+	if err := json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+	"wb-parser/internal/model"
+)
+
+const (
+	StatusQueued   = "queued"
+	StatusRunning  = "running"
+	StatusDone     = "done"
+	StatusFailed   = "failed"
+	StatusCanceled = "canceled"
+)
+
+type JobRequest struct {
+	Marketplace string `json:"marketplace"`
+	Url         string `json:"url"`
+	Query       string `json:"query"` // used instead of url when set
+	Pages       int    `json:"pages"`
+	Region      string `json:"region"`
+}
+
+// JobInfo is a job state returned by the API.
+type JobInfo struct {
+	ID         string     `json:"id"`
+	Request    JobRequest `json:"request"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	PagesDone  int        `json:"pages_done"`
+	Products   int        `json:"products"`
+	Output     string     `json:"output"` // directory of the result files
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type Job struct {
+	mu       sync.Mutex
+	info     JobInfo
+	products []*model.ProductCard
+	cancel   context.CancelFunc
+	changed  chan struct{} // closed and replaced on every change
+}
+
+func newJob(id string, req JobRequest) *Job {
+	return &Job{
+		info: JobInfo{
+			ID:        id,
+			Request:   req,
+			Status:    StatusQueued,
+			CreatedAt: time.Now(),
+		},
+		changed: make(chan struct{}),
+	}
+}
+
+func (j *Job) Info() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.info
+}
+
+// Results returns products starting from the offset, a channel closed on
+// the next change and whether the job is finished.
+func (j *Job) Results(offset int) ([]*model.ProductCard, <-chan struct{}, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var products []*model.ProductCard
+	if offset < len(j.products) {
+		products = j.products[offset:]
+	}
+	return products, j.changed, j.finished()
+}
+
+func (j *Job) finished() bool {
+	switch j.info.Status {
+	case StatusDone, StatusFailed, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+// start marks the job as running unless it was canceled in the queue.
+func (j *Job) start(cancel context.CancelFunc) bool {
+	return j.update(func() bool {
+		if j.info.Status != StatusQueued {
+			return false
+		}
+		j.cancel = cancel
+		j.info.Status = StatusRunning
+		now := time.Now()
+		j.info.StartedAt = &now
+		return true
+	})
+}
+
+func (j *Job) addPage(products []*model.ProductCard) {
+	j.update(func() bool {
+		j.products = append(j.products, products...)
+		j.info.PagesDone++
+		j.info.Products = len(j.products)
+		return true
+	})
+}
+
+func (j *Job) finish(err error) {
+	j.update(func() bool {
+		if j.info.Status == StatusRunning {
+			j.info.Status = StatusDone
+		}
+		if err != nil && j.info.Status != StatusCanceled {
+			j.info.Status = StatusFailed
+			j.info.Error = err.Error()
+		}
+		now := time.Now()
+		j.info.FinishedAt = &now
+		return true
+	})
+}
+
+// Cancel stops the running job or removes it from the queue.
+func (j *Job) Cancel() {
+	j.update(func() bool {
+		if j.finished() {
+			return false
+		}
+		j.info.Status = StatusCanceled
+		if j.cancel != nil {
+			j.cancel()
+			return true
+		}
+		// the job is still queued, the worker will skip it
+		now := time.Now()
+		j.info.FinishedAt = &now
+		return true
+	})
+}
+
+func (j *Job) update(fn func() bool) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !fn() {
+		return false
+	}
+	close(j.changed)
+	j.changed = make(chan struct{})
+	return true
+}
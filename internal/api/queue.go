@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"wb-parser/internal/model"
+	"wb-parser/internal/service"
+)
+
+var ErrQueueFull = errors.New("job queue is full")
+
+// Retention limits finished jobs kept in memory with their products for the
+// status and results requests.
+type Retention struct {
+	TTL time.Duration // since the job finished, 0 keeps jobs forever
+	Max int           // finished jobs, the oldest ones are dropped first, 0 is unlimited
+}
+
+// Queue runs submitted jobs by a fixed number of workers.
+type Queue struct {
+	mu        sync.Mutex
+	jobs      map[string]*Job
+	queue     chan *Job
+	retention Retention
+	output    string
+	opts      []service.Option
+}
+
+// NewQueue creates the queue of size jobs. Every job writes its results into
+// its own directory output/<job id>, so concurrent jobs do not overwrite each
+// other's files.
+func NewQueue(size int, retention Retention, output string, opts ...service.Option) *Queue {
+	return &Queue{
+		jobs:      map[string]*Job{},
+		queue:     make(chan *Job, size),
+		retention: retention,
+		output:    output,
+		opts:      opts,
+	}
+}
+
+// Run starts workers and blocks until the context is canceled.
+func (q *Queue) Run(ctx context.Context, workers int) {
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				q.evict(now)
+			}
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-q.queue:
+					q.run(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *Queue) Submit(req JobRequest) (*Job, error) {
+	if _, err := service.NewCatalogService(req.Marketplace); err != nil {
+		return nil, err
+	}
+	if req.Url == "" && req.Query == "" {
+		return nil, errors.New("url or query is required")
+	}
+	if req.Pages <= 0 {
+		req.Pages = 30
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	job := newJob(hex.EncodeToString(id), req)
+	job.info.Output = filepath.Join(q.output, job.info.ID)
+
+	select {
+	case q.queue <- job:
+	default:
+		return nil, ErrQueueFull
+	}
+	q.mu.Lock()
+	q.jobs[job.info.ID] = job
+	q.mu.Unlock()
+	return job, nil
+}
+
+func (q *Queue) Get(id string) (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	return job, ok
+}
+
+func (q *Queue) List() []JobInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	infos := []JobInfo{}
+	for _, job := range q.jobs {
+		infos = append(infos, job.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) {
+	jctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if !job.start(cancel) {
+		return
+	}
+
+	opts := append([]service.Option{
+		service.WithProgress(func(page int, products []*model.ProductCard) {
+			job.addPage(products)
+		}),
+		service.WithRegion(job.info.Request.Region),
+	}, q.opts...)
+	s, err := service.NewCatalogService(job.info.Request.Marketplace, opts...)
+	if err != nil {
+		job.finish(err)
+		return
+	}
+
+	url := job.info.Request.Url
+	if job.info.Request.Query != "" {
+		url = s.SearchURL(&model.SearchQuery{Text: job.info.Request.Query})
+	}
+	job.finish(s.Parse(jctx, url, job.info.Request.Pages, job.info.Output))
+	q.evict(time.Now())
+}
+
+// evict drops finished jobs beyond the retention.
+func (q *Queue) evict(now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	finished := []JobInfo{}
+	for id, job := range q.jobs {
+		info := job.Info()
+		if info.FinishedAt == nil {
+			continue
+		}
+		if q.retention.TTL > 0 && now.Sub(*info.FinishedAt) > q.retention.TTL {
+			delete(q.jobs, id)
+			continue
+		}
+		finished = append(finished, info)
+	}
+	if q.retention.Max <= 0 || len(finished) <= q.retention.Max {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+	for _, info := range finished[:len(finished)-q.retention.Max] {
+		delete(q.jobs, info.ID)
+	}
+}
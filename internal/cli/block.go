@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+	"wb-parser/internal/service"
+)
+
+// BlockFlags holds the command line options of the reaction on captcha and
+// anti-bot pages.
+type BlockFlags struct {
+	action   string
+	attempts int
+	backoff  time.Duration
+	timeout  time.Duration
+	proxies  string
+}
+
+func RegisterBlockFlags(fs *flag.FlagSet) *BlockFlags {
+	f := &BlockFlags{}
+	fs.StringVar(&f.action, "on-block", service.BlockBackoff, "Reaction on captcha and anti-bot pages: fail, backoff, rotate, wait")
+	fs.IntVar(&f.attempts, "block-attempts", 3, "Max reactions on a blocked page")
+	fs.DurationVar(&f.backoff, "block-backoff", 30*time.Second, "Initial pause for -on-block backoff, doubled on every attempt")
+	fs.DurationVar(&f.timeout, "block-wait", 5*time.Minute, "Max time to solve the captcha for -on-block wait")
+	fs.StringVar(&f.proxies, "proxies", "", "Proxies separated by comma, rotated by -on-block rotate")
+	return f
+}
+
+func (f *BlockFlags) Option() (service.Option, error) {
+	switch f.action {
+	case service.BlockFail, service.BlockBackoff, service.BlockRotate, service.BlockWait:
+	default:
+		return nil, fmt.Errorf("unknown -on-block %q, expected fail, backoff, rotate or wait", f.action)
+	}
+	proxies := []string{}
+	for _, proxy := range strings.Split(f.proxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return service.WithBlockPolicy(service.BlockPolicy{
+		Action:   f.action,
+		Attempts: f.attempts,
+		Backoff:  f.backoff,
+		Timeout:  f.timeout,
+		Proxies:  proxies,
+	}), nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"wb-parser/internal/service"
+	"wb-parser/package/money"
+)
+
+// CurrencyFlags holds the target currency of the prices and the rates file.
+type CurrencyFlags struct {
+	currency string
+	rates    string
+}
+
+func RegisterCurrencyFlags(fs *flag.FlagSet) *CurrencyFlags {
+	f := &CurrencyFlags{}
+	fs.StringVar(&f.currency, "currency", "", "Convert prices to the currency, e.g. RUB, requires -rates (optional)")
+	fs.StringVar(&f.rates, "rates", "", `JSON file with exchange rates, e.g. {"base": "RUB", "rates": {"USD": 92.5}}`)
+	return f
+}
+
+func (f *CurrencyFlags) Option() (service.Option, error) {
+	if f.currency == "" {
+		return service.WithCurrency("", nil), nil
+	}
+	if f.rates == "" {
+		return nil, errors.New("-currency requires -rates")
+	}
+	rates, err := money.LoadRates(f.rates)
+	if err != nil {
+		return nil, err
+	}
+	return service.WithCurrency(f.currency, rates), nil
+}
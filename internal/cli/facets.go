@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"wb-parser/internal/model"
+	"wb-parser/internal/service"
+)
+
+// FacetFlags holds the sidebar filters mode and the facet to split crawls by.
+type FacetFlags struct {
+	list  bool
+	split string
+}
+
+func RegisterFacetFlags(fs *flag.FlagSet) *FacetFlags {
+	f := &FacetFlags{}
+	fs.BoolVar(&f.list, "facets", false, "Write the sidebar filters of the catalog with their counts instead of crawling")
+	fs.StringVar(&f.split, "split-facet", "", `Crawl the catalog once per value of the filter, e.g. "Бренд" (optional)`)
+	return f
+}
+
+// List reports whether facets are written instead of crawling products.
+func (f *FacetFlags) List() bool {
+	return f.list
+}
+
+// Split returns the facet to split crawls by, empty when crawls are not split.
+func (f *FacetFlags) Split() string {
+	return f.split
+}
+
+func (f *FacetFlags) Option() service.Option {
+	return service.WithFacetSplit(f.split)
+}
+
+// WriteFacets writes a facet, value, count and url row per facet option.
+func WriteFacets(output string, marketplace string, facets []*model.Facet) error {
+	if err := os.MkdirAll(output, os.ModePerm); err != nil {
+		return err
+	}
+	filepath := fmt.Sprintf("%s/%s-facets-%s.csv", output, marketplace, time.Now().Format("2006-01-02_15-04-05"))
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	csvw := csv.NewWriter(f)
+	if err := csvw.Write([]string{"facet", "value", "count", "url"}); err != nil {
+		return err
+	}
+	for _, facet := range facets {
+		for _, v := range facet.Values {
+			if err := csvw.Write([]string{facet.Name, v.Name, strconv.Itoa(v.Count), v.Url}); err != nil {
+				return err
+			}
+		}
+	}
+	csvw.Flush()
+	return csvw.Error()
+}
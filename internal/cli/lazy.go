@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"flag"
+	"time"
+	"wb-parser/internal/service"
+)
+
+// LazyFlags holds the budget of waiting for lazy loaded images and prices.
+type LazyFlags struct {
+	card time.Duration
+	page time.Duration
+}
+
+func RegisterLazyFlags(fs *flag.FlagSet) *LazyFlags {
+	f := &LazyFlags{}
+	fs.DurationVar(&f.card, "lazy-card", service.DefaultLazyCardBudget, "Max wait for the lazy loaded image and price of a single card")
+	fs.DurationVar(&f.page, "lazy-page", service.DefaultLazyPageBudget, "Max wait for lazy loaded content of all cards of a page, 0 to flag incomplete cards without waiting")
+	return f
+}
+
+func (f *LazyFlags) Option() service.Option {
+	return service.WithLazyBudget(f.card, f.page)
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"flag"
+	"wb-parser/internal/service"
+	chromedputils "wb-parser/package/chromedp_utils"
+)
+
+// ProfileFlags holds the persistent browser profile and cookie files.
+type ProfileFlags struct {
+	profile     string
+	profilesDir string
+	importFile  string
+	exportFile  string
+}
+
+func RegisterProfileFlags(fs *flag.FlagSet) *ProfileFlags {
+	f := &ProfileFlags{}
+	fs.StringVar(&f.profile, "profile", "", "Named persistent Chrome profile keeping cookies and logins between runs (optional)")
+	fs.StringVar(&f.profilesDir, "profiles-dir", chromedputils.DefaultProfilesDir, "Directory of the named profiles")
+	fs.StringVar(&f.importFile, "cookies-import", "", "JSON or Netscape cookies.txt file loaded into the browser before the crawl (optional)")
+	fs.StringVar(&f.exportFile, "cookies-export", "", "File to write the browser cookies to after the crawl, Netscape format for .txt and JSON otherwise (optional)")
+	return f
+}
+
+// ProfileDir returns the user-data-dir of the selected profile, empty when
+// no profile is used.
+func (f *ProfileFlags) ProfileDir() (string, error) {
+	if f.profile == "" {
+		return "", nil
+	}
+	return chromedputils.ProfileDir(f.profilesDir, f.profile)
+}
+
+func (f *ProfileFlags) Options() ([]service.Option, error) {
+	dir, err := f.ProfileDir()
+	if err != nil {
+		return nil, err
+	}
+	return []service.Option{
+		service.WithProfile(dir),
+		service.WithCookies(f.importFile, f.exportFile),
+	}, nil
+}
+
+// CookieFiles returns the cookie import and export files.
+func (f *ProfileFlags) CookieFiles() (string, string) {
+	return f.importFile, f.exportFile
+}
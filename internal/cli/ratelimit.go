@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"flag"
+	"time"
+	"wb-parser/internal/service"
+	"wb-parser/package/ratelimit"
+)
+
+// RateFlags holds the command line options of polite crawling.
+type RateFlags struct {
+	rpm        int
+	minDelay   time.Duration
+	maxDelay   time.Duration
+	dailyQuota int
+	file       string
+	quotaFile  string
+}
+
+func RegisterRateFlags(fs *flag.FlagSet) *RateFlags {
+	f := &RateFlags{}
+	fs.IntVar(&f.rpm, "rpm", 0, "Max page requests per minute, unlimited if 0")
+	fs.DurationVar(&f.minDelay, "delay-min", 0, "Min random delay between pages")
+	fs.DurationVar(&f.maxDelay, "delay-max", 0, "Max random delay between pages")
+	fs.IntVar(&f.dailyQuota, "daily-quota", 0, "Max page requests per day, unlimited if 0")
+	fs.StringVar(&f.file, "rate-limits", "", "JSON file with per-marketplace limits, overrides the flags above")
+	fs.StringVar(&f.quotaFile, "quota-file", "output/quota.json", "JSON file keeping daily quota counts between runs, in memory only if empty")
+	return f
+}
+
+func (f *RateFlags) Option(marketplace string) (service.Option, error) {
+	registry := ratelimit.NewRegistry(map[string]ratelimit.Config{
+		marketplace: {
+			RequestsPerMinute: f.rpm,
+			MinDelay:          f.minDelay,
+			MaxDelay:          f.maxDelay,
+			DailyQuota:        f.dailyQuota,
+		},
+	})
+	if f.file != "" {
+		var err error
+		registry, err = ratelimit.LoadRegistry(f.file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if f.quotaFile != "" {
+		registry.PersistQuota(f.quotaFile)
+	}
+	return service.WithRateLimits(registry), nil
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+	"wb-parser/internal/service"
+)
+
+// RegionFlags holds the delivery region of the crawl or the list of regions
+// to compare prices in.
+type RegionFlags struct {
+	region  string
+	regions string
+}
+
+func RegisterRegionFlags(fs *flag.FlagSet, usage string) *RegionFlags {
+	f := &RegionFlags{}
+	fs.StringVar(&f.region, "region", "", usage+" (optional)")
+	fs.StringVar(&f.regions, "regions", "", "Comma separated regions to crawl one by one and compare prices in, replaces -region (optional)")
+	return f
+}
+
+func (f *RegionFlags) Option() service.Option {
+	return service.WithRegion(f.region)
+}
+
+// Regions returns the regions to compare, empty when a single region is crawled.
+func (f *RegionFlags) Regions() []string {
+	regions := []string{}
+	for _, region := range strings.Split(f.regions, ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
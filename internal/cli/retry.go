@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"flag"
+	"time"
+	"wb-parser/internal/service"
+)
+
+// RetryFlags holds the command line options of the retry policy.
+type RetryFlags struct {
+	attempts int
+	backoff  time.Duration
+}
+
+func RegisterRetryFlags(fs *flag.FlagSet) *RetryFlags {
+	f := &RetryFlags{}
+	policy := service.DefaultRetryPolicy()
+	fs.IntVar(&f.attempts, "retries", policy.Attempts, "Attempts to load a page, wait for and extract the product cards")
+	fs.DurationVar(&f.backoff, "retry-backoff", policy.Backoff, "Initial pause between attempts, doubled on every attempt")
+	return f
+}
+
+func (f *RetryFlags) Option() service.Option {
+	policy := service.DefaultRetryPolicy()
+	policy.Attempts = f.attempts
+	policy.Backoff = f.backoff
+	return service.WithRetryPolicy(policy)
+}
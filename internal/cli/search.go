@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"wb-parser/internal/model"
+	"wb-parser/internal/service"
+)
+
+// SearchFlags holds the command line options of the search-by-keyword mode.
+type SearchFlags struct {
+	query    string
+	queries  string
+	sort     string
+	priceMin int
+	priceMax int
+	filters  filterFlag
+}
+
+func RegisterSearchFlags(fs *flag.FlagSet) *SearchFlags {
+	f := &SearchFlags{filters: filterFlag{}}
+	fs.StringVar(&f.query, "query", "", "Search keywords separated by comma (replaces -url)")
+	fs.StringVar(&f.queries, "queries", "", "Path to a file with one search keyword per line")
+	fs.StringVar(&f.sort, "sort", "", "Sort order: popular, rating, price_asc, price_desc, new")
+	fs.IntVar(&f.priceMin, "price-min", 0, "Min price")
+	fs.IntVar(&f.priceMax, "price-max", 0, "Max price")
+	fs.Var(f.filters, "filter", "Additional search url parameter key=value (can be repeated)")
+	return f
+}
+
+// Queries returns search queries for every keyword passed via -query and
+// -queries. Empty result means the search mode is off. The sort order and the
+// price range are checked against the marketplace search.
+func (f *SearchFlags) Queries(marketplace string) ([]*model.SearchQuery, error) {
+	if err := service.CheckSort(marketplace, f.sort); err != nil {
+		return nil, err
+	}
+	if f.priceMin < 0 || f.priceMax < 0 {
+		return nil, errors.New("-price-min and -price-max can not be negative")
+	}
+	if f.priceMax > 0 && f.priceMin > f.priceMax {
+		return nil, fmt.Errorf("-price-min %d is greater than -price-max %d", f.priceMin, f.priceMax)
+	}
+	keywords := strings.Split(f.query, ",")
+	if f.queries != "" {
+		file, err := os.Open(f.queries)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			keywords = append(keywords, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	queries := []*model.SearchQuery{}
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		queries = append(queries, &model.SearchQuery{
+			Text:     keyword,
+			Sort:     f.sort,
+			PriceMin: f.priceMin,
+			PriceMax: f.priceMax,
+			Filters:  f.filters,
+		})
+	}
+	return queries, nil
+}
+
+// QueryOutput returns a separate output directory for the query results.
+func QueryOutput(output string, q *model.SearchQuery) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, q.Text)
+	return filepath.Join(output, name)
+}
+
+type filterFlag map[string]string
+
+func (f filterFlag) String() string {
+	pairs := []string{}
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f filterFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("invalid filter %q, expected key=value", value)
+	}
+	f[k] = v
+	return nil
+}
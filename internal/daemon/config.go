@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+	"wb-parser/package/ratelimit"
+)
+
+type Config struct {
+	// Status is a path of the file with persistent job status.
+	Status string `json:"status"`
+	// Jitter is a max random delay added to every scheduled run.
+	Jitter Duration `json:"jitter"`
+	// RateLimits are shared by all jobs of the marketplace.
+	RateLimits map[string]ratelimit.Config `json:"rate_limits"`
+	// QuotaFile is a path of the file with daily quota counts.
+	QuotaFile string `json:"quota_file"`
+	Jobs      []*Job `json:"jobs"`
+}
+
+type Job struct {
+	Name        string `json:"name"`
+	Marketplace string `json:"marketplace"`
+	Url         string `json:"url"`
+	Query       string `json:"query"` // used instead of url when set
+	Pages       int    `json:"pages"`
+	Region      string `json:"region"`
+	Schedule    string `json:"schedule"` // cron expression, e.g. "0 */6 * * *"
+	Output      string `json:"output"`
+	DB          string `json:"db"`
+	Alerts      string `json:"alerts"`
+}
+
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{Status: "output/daemon-status.json", QuotaFile: "output/quota.json"}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, job := range cfg.Jobs {
+		if job.Name == "" || names[job.Name] {
+			return nil, fmt.Errorf("job name %q is empty or not unique", job.Name)
+		}
+		names[job.Name] = true
+		if job.Url == "" && job.Query == "" {
+			return nil, fmt.Errorf("job %s: url or query is required", job.Name)
+		}
+		if job.Pages == 0 {
+			job.Pages = 30
+		}
+		if job.Output == "" {
+			job.Output = "output"
+		}
+		if job.Alerts != "" && job.DB == "" {
+			return nil, fmt.Errorf("job %s: alerts require db", job.Name)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,164 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+	"wb-parser/internal/alert"
+	"wb-parser/internal/model"
+	"wb-parser/internal/service"
+	"wb-parser/internal/storage"
+	"wb-parser/package/ratelimit"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Daemon runs configured crawl jobs on their cron schedules. A job is skipped
+// when its previous run is still in progress.
+type Daemon struct {
+	cfg        *Config
+	status     *StatusStore
+	storages   map[string]*storage.SQLiteStorage
+	rateLimits *ratelimit.Registry
+}
+
+func New(cfg *Config) (*Daemon, error) {
+	status, err := OpenStatusStore(cfg.Status)
+	if err != nil {
+		return nil, err
+	}
+	rateLimits := ratelimit.NewRegistry(cfg.RateLimits)
+	if cfg.QuotaFile != "" {
+		rateLimits.PersistQuota(cfg.QuotaFile)
+	}
+	return &Daemon{
+		cfg:        cfg,
+		status:     status,
+		storages:   map[string]*storage.SQLiteStorage{},
+		rateLimits: rateLimits,
+	}, nil
+}
+
+// Run schedules the jobs and blocks until the context is canceled and the
+// running jobs are finished.
+func (d *Daemon) Run(ctx context.Context) error {
+	defer d.close()
+
+	c := cron.New()
+	runners := []*runner{}
+	for _, job := range d.cfg.Jobs {
+		s, err := d.service(job)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		r := &runner{daemon: d, job: job, service: s}
+		id, err := c.AddFunc(job.Schedule, func() { r.run(ctx) })
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		r.entry = func() cron.Entry { return c.Entry(id) }
+		runners = append(runners, r)
+	}
+
+	c.Start()
+	for _, r := range runners {
+		r.update(func(status *JobStatus) {})
+	}
+	<-ctx.Done()
+	<-c.Stop().Done()
+	return nil
+}
+
+func (d *Daemon) service(job *Job) (service.CatalogService, error) {
+	opts := []service.Option{service.WithRateLimits(d.rateLimits), service.WithRegion(job.Region)}
+	if job.DB != "" {
+		st, ok := d.storages[job.DB]
+		if !ok {
+			var err error
+			if st, err = storage.NewSQLiteStorage(job.DB); err != nil {
+				return nil, err
+			}
+			d.storages[job.DB] = st
+		}
+
+		var sink service.Sink = st
+		if job.Alerts != "" {
+			cfg, err := alert.LoadConfig(job.Alerts)
+			if err != nil {
+				return nil, err
+			}
+			sink = alert.NewEngine(st, cfg.Rules, cfg.Notifiers())
+		}
+		opts = append(opts, service.WithSink(sink))
+	}
+	return service.NewCatalogService(job.Marketplace, opts...)
+}
+
+func (d *Daemon) close() {
+	for _, st := range d.storages {
+		st.Close()
+	}
+}
+
+type runner struct {
+	daemon  *Daemon
+	job     *Job
+	service service.CatalogService
+	entry   func() cron.Entry
+	mu      sync.Mutex
+}
+
+func (r *runner) run(ctx context.Context) {
+	if !r.mu.TryLock() {
+		fmt.Printf("job %s: previous run is still in progress, skipped\n", r.job.Name)
+		r.update(func(status *JobStatus) { status.Skipped++ })
+		return
+	}
+	defer r.mu.Unlock()
+
+	if jitter := r.daemon.cfg.Jitter.Duration; jitter > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(rand.Int63n(int64(jitter)))):
+		}
+	}
+
+	r.update(func(status *JobStatus) {
+		status.Running = true
+		status.LastStart = time.Now()
+	})
+	err := r.service.Parse(ctx, r.url(), r.job.Pages, r.job.Output)
+	if err != nil {
+		fmt.Printf("job %s: %s\n", r.job.Name, err)
+	}
+	r.update(func(status *JobStatus) {
+		status.Running = false
+		status.LastFinish = time.Now()
+		status.Runs++
+		status.LastError = ""
+		if err != nil {
+			status.Failures++
+			status.LastError = err.Error()
+		}
+	})
+}
+
+func (r *runner) url() string {
+	if r.job.Query != "" {
+		return r.service.SearchURL(&model.SearchQuery{Text: r.job.Query})
+	}
+	return r.job.Url
+}
+
+func (r *runner) update(fn func(status *JobStatus)) {
+	err := r.daemon.status.Update(r.job.Name, func(status *JobStatus) {
+		fn(status)
+		status.NextRun = r.entry().Next
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
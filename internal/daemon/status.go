@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type JobStatus struct {
+	Running    bool      `json:"running"`
+	LastStart  time.Time `json:"last_start"`
+	LastFinish time.Time `json:"last_finish"`
+	LastError  string    `json:"last_error"`
+	NextRun    time.Time `json:"next_run"`
+	Runs       int       `json:"runs"`
+	Failures   int       `json:"failures"`
+	Skipped    int       `json:"skipped"` // runs skipped because the previous one was still running
+}
+
+// StatusStore keeps job status in a JSON file so it survives restarts.
+type StatusStore struct {
+	mu   sync.Mutex
+	path string
+	jobs map[string]*JobStatus
+}
+
+func OpenStatusStore(path string) (*StatusStore, error) {
+	s := &StatusStore{path: path, jobs: map[string]*JobStatus{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.jobs); err != nil {
+		return nil, err
+	}
+	// the daemon was stopped in the middle of these runs
+	for _, status := range s.jobs {
+		if status.Running {
+			status.Running = false
+			status.LastError = "interrupted"
+		}
+	}
+	return s, nil
+}
+
+// Update changes the job status and writes the file.
+func (s *StatusStore) Update(name string, fn func(status *JobStatus)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, ok := s.jobs[name]
+	if !ok {
+		status = &JobStatus{}
+		s.jobs[name] = status
+	}
+	fn(status)
+
+	data, err := json.MarshalIndent(s.jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"math"
+	"wb-parser/internal/model"
+)
+
+const (
+	KindNew         = "new"
+	KindDisappeared = "disappeared"
+	KindPrice       = "price"
+	KindRating      = "rating"
+)
+
+// Change is a single difference of the product between two runs.
+type Change struct {
+	Kind         string   `json:"kind"`
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Url          string   `json:"url"`
+	OldPrice     *float64 `json:"old_price,omitempty"`
+	NewPrice     *float64 `json:"new_price,omitempty"`
+	PriceChange  *float64 `json:"price_change,omitempty"` // percent
+	OldRate      *float64 `json:"old_rate,omitempty"`
+	NewRate      *float64 `json:"new_rate,omitempty"`
+	ReviewsDelta *float64 `json:"reviews_delta,omitempty"`
+}
+
+type Options struct {
+	// PriceThreshold is the min price change in percent to be reported.
+	PriceThreshold float64
+}
+
+// Compare reports new and disappeared products, price changes above the
+// threshold and rating or reviews changes. Products are matched by id, or by
+// url when either side has no ids, e.g. a csv file of an older version.
+func Compare(before []*model.ProductCard, after []*model.ProductCard, opts Options) []*Change {
+	key := keyByUrl
+	if hasIDs(before) && hasIDs(after) {
+		key = (*model.ProductCard).Key
+	}
+	oldIndex := index(before, key)
+	newIndex := index(after, key)
+	changes := []*Change{}
+
+	for _, product := range after {
+		prev, ok := oldIndex[key(product)]
+		if !ok {
+			changes = append(changes, &Change{
+				Kind:     KindNew,
+				ID:       product.ID,
+				Title:    product.Title,
+				Url:      product.Url,
+				NewPrice: number(product.Price),
+				NewRate:  number(product.Rate),
+			})
+			continue
+		}
+
+		oldPrice, newPrice := number(prev.Price), number(product.Price)
+		if oldPrice != nil && newPrice != nil && *oldPrice != 0 {
+			change := (*newPrice - *oldPrice) / *oldPrice * 100
+			if math.Abs(change) >= opts.PriceThreshold && change != 0 {
+				changes = append(changes, &Change{
+					Kind:        KindPrice,
+					ID:          product.ID,
+					Title:       product.Title,
+					Url:         product.Url,
+					OldPrice:    oldPrice,
+					NewPrice:    newPrice,
+					PriceChange: &change,
+				})
+			}
+		}
+
+		oldRate, newRate := number(prev.Rate), number(product.Rate)
+		oldReviews, newReviews := number(prev.Reviews), number(product.Reviews)
+		rateChanged := oldRate != nil && newRate != nil && *oldRate != *newRate
+		reviewsChanged := oldReviews != nil && newReviews != nil && *oldReviews != *newReviews
+		if rateChanged || reviewsChanged {
+			c := &Change{
+				Kind:    KindRating,
+				ID:      product.ID,
+				Title:   product.Title,
+				Url:     product.Url,
+				OldRate: oldRate,
+				NewRate: newRate,
+			}
+			if reviewsChanged {
+				delta := *newReviews - *oldReviews
+				c.ReviewsDelta = &delta
+			}
+			changes = append(changes, c)
+		}
+	}
+
+	for _, product := range before {
+		if _, ok := newIndex[key(product)]; !ok {
+			changes = append(changes, &Change{
+				Kind:     KindDisappeared,
+				ID:       product.ID,
+				Title:    product.Title,
+				Url:      product.Url,
+				OldPrice: number(product.Price),
+				OldRate:  number(product.Rate),
+			})
+		}
+	}
+	return changes
+}
+
+func index(products []*model.ProductCard, key func(*model.ProductCard) string) map[string]*model.ProductCard {
+	res := map[string]*model.ProductCard{}
+	for _, product := range products {
+		res[key(product)] = product
+	}
+	return res
+}
+
+func hasIDs(products []*model.ProductCard) bool {
+	for _, product := range products {
+		if product.ID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func keyByUrl(product *model.ProductCard) string {
+	return product.Url
+}
+
+func number(value string) *float64 {
+	f, ok := model.Number(value)
+	if !ok {
+		return nil
+	}
+	return &f
+}
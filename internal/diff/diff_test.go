@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"testing"
+	"wb-parser/internal/model"
+)
+
+func kinds(changes []*Change) map[string]string {
+	res := map[string]string{}
+	for _, c := range changes {
+		res[c.Url] += c.Kind
+	}
+	return res
+}
+
+func TestCompare(t *testing.T) {
+	before := []*model.ProductCard{
+		{ID: "1", Url: "https://example.com/1", Price: "100", Rate: "4.5", Reviews: "10"},
+		{ID: "2", Url: "https://example.com/2", Price: "200", Rate: "4.0", Reviews: "5"},
+		{ID: "3", Url: "https://example.com/3", Price: "300"},
+		{ID: "4", Url: "https://example.com/4", Price: "400"},
+	}
+	after := []*model.ProductCard{
+		{ID: "1", Url: "https://example.com/1", Price: "80", Rate: "4.5", Reviews: "10"},
+		{ID: "2", Url: "https://example.com/2", Price: "201", Rate: "4.2", Reviews: "7"},
+		{ID: "3", Url: "https://example.com/3?utm=x", Price: "300"},
+		{ID: "5", Url: "https://example.com/5", Price: "500"},
+	}
+	got := kinds(Compare(before, after, Options{PriceThreshold: 5}))
+	want := map[string]string{
+		"https://example.com/1": KindPrice,
+		"https://example.com/2": KindRating,
+		"https://example.com/5": KindNew,
+		"https://example.com/4": KindDisappeared,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	for url, kind := range want {
+		if got[url] != kind {
+			t.Errorf("%s: %q, want %q", url, got[url], kind)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	before := []*model.ProductCard{{ID: "1", Url: "u1", Price: "200", Rate: "4,0", Reviews: "5"}}
+	after := []*model.ProductCard{{ID: "1", Url: "u1", Price: "150", Rate: "4,5", Reviews: "8"}}
+	changes := Compare(before, after, Options{PriceThreshold: 10})
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	price, rating := changes[0], changes[1]
+	if price.Kind != KindPrice || *price.OldPrice != 200 || *price.NewPrice != 150 || *price.PriceChange != -25 {
+		t.Errorf("price change = %+v", price)
+	}
+	if rating.Kind != KindRating || *rating.OldRate != 4 || *rating.NewRate != 4.5 || *rating.ReviewsDelta != 3 {
+		t.Errorf("rating change = %+v", rating)
+	}
+}
+
+func TestCompareThreshold(t *testing.T) {
+	before := []*model.ProductCard{{ID: "1", Url: "u1", Price: "100"}}
+	after := []*model.ProductCard{{ID: "1", Url: "u1", Price: "96"}}
+	if changes := Compare(before, after, Options{PriceThreshold: 5}); len(changes) != 0 {
+		t.Errorf("4%% change reported with 5%% threshold: %+v", changes[0])
+	}
+}
+
+func TestCompareWithoutIDs(t *testing.T) {
+	// a csv of an older version has no id column
+	before := []*model.ProductCard{
+		{Url: "https://example.com/1", Price: "100"},
+		{Url: "https://example.com/2", Price: "200"},
+	}
+	after := []*model.ProductCard{
+		{ID: "1", Url: "https://example.com/1", Price: "100"},
+		{ID: "3", Url: "https://example.com/3", Price: "300"},
+	}
+	got := kinds(Compare(before, after, Options{PriceThreshold: 5}))
+	want := map[string]string{
+		"https://example.com/3": KindNew,
+		"https://example.com/2": KindDisappeared,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	for url, kind := range want {
+		if got[url] != kind {
+			t.Errorf("%s: %q, want %q", url, got[url], kind)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+var columns = []string{
+	"kind", "id", "title", "url", "old_price", "new_price", "price_change", "old_rate", "new_rate", "reviews_delta",
+}
+
+func Write(w io.Writer, format string, changes []*Change) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, changes)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	case FormatMarkdown:
+		return writeMarkdown(w, changes)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeCSV(w io.Writer, changes []*Change) error {
+	csvw := csv.NewWriter(w)
+	if err := csvw.Write(columns); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := csvw.Write(c.row()); err != nil {
+			return err
+		}
+	}
+	csvw.Flush()
+	return csvw.Error()
+}
+
+func writeMarkdown(w io.Writer, changes []*Change) error {
+	if _, err := fmt.Fprintf(w, "| %s |\n|%s\n", strings.Join(columns, " | "), strings.Repeat(" --- |", len(columns))); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		row := c.row()
+		for i, cell := range row {
+			row[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Change) row() []string {
+	return []string{
+		c.Kind,
+		c.ID,
+		c.Title,
+		c.Url,
+		formatNumber(c.OldPrice),
+		formatNumber(c.NewPrice),
+		formatNumber(c.PriceChange),
+		formatNumber(c.OldRate),
+		formatNumber(c.NewRate),
+		formatNumber(c.ReviewsDelta),
+	}
+}
+
+func formatNumber(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
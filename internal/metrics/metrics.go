@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"wb-parser/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ec_parser"
+
+var (
+	PagesFetched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pages_fetched_total",
+		Help:      "Catalog pages successfully navigated to.",
+	}, []string{"marketplace"})
+
+	CardsFound = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cards_found_total",
+		Help:      "Product cards found on catalog pages.",
+	}, []string{"marketplace"})
+
+	CardsParsed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cards_parsed_total",
+		Help:      "Product cards successfully parsed.",
+	}, []string{"marketplace"})
+
+	EmptyFields = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "empty_fields_total",
+		Help:      "Parsed product cards with an empty field.",
+	}, []string{"marketplace", "field"})
+
+	NavigationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "navigation_seconds",
+		Help:      "Catalog page navigation latency.",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60},
+	}, []string{"marketplace"})
+
+	Timeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "timeouts_total",
+		Help:      "Browser actions interrupted by timeout.",
+	}, []string{"marketplace"})
+
+	CaptchaHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "captcha_hits_total",
+		Help:      "Captcha or anti-bot pages served instead of the catalog.",
+	}, []string{"marketplace"})
+
+	ChromeRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chrome_restarts_total",
+		Help:      "Chrome restarts after a block page.",
+	}, []string{"marketplace"})
+)
+
+// ObserveProduct counts the parsed card and its empty fields.
+func ObserveProduct(marketplace string, product *model.ProductCard) {
+	CardsParsed.WithLabelValues(marketplace).Inc()
+	fields := map[string]string{
+		"url":        product.Url,
+		"title":      product.Title,
+		"image":      product.Image,
+		"price":      product.Price,
+		"full_price": product.FullPrice,
+		"rate":       product.Rate,
+		"reviews":    product.Reviews,
+	}
+	for field, value := range fields {
+		if value == "" {
+			EmptyFields.WithLabelValues(marketplace, field).Inc()
+		}
+	}
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Serve exposes /metrics on the address in background.
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Println(err)
+		}
+	}()
+}
@@ -1,11 +1,39 @@
 package model
 
 type ProductCard struct {
+	ID        string // marketplace product id
 	Url       string
 	Title     string
 	Image     string
 	Price     string
 	FullPrice string
+	CardPrice string // price paid with Ozon Card or WB Wallet, empty when not shown
+	Discount  string // percent, shown on the card or computed from the prices
 	Rate      string
 	Reviews   string
+	Page      int
+	Position  int      // absolute position in the listing starting from 1
+	Promoted  bool     // advertised or sponsored card
+	Labels    []string // promo badges like "Распродажа", coupons and cashback
+	// Incomplete card was captured with a placeholder image or an empty price
+	Incomplete   bool
+	Delivery     string // delivery text of the card
+	DeliveryDate string // earliest expected delivery date, YYYY-MM-DD
+	DeliveryDays string // days from the crawl to DeliveryDate
+	Fulfilment   string // fbo for the marketplace warehouse, fbs for the seller one
+	Brand        string // brand of the brand page the card was listed on
+	Seller       string // seller of the storefront the card was listed on
+	Currency     string // ISO code of the prices, empty when not detected
+	Region       string // delivery region prices were shown for, empty for the default one
+}
+
+// Category is a node of the marketplace category tree.
+type Category struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id,omitempty"`
+	Name     string `json:"name"`
+	Url      string `json:"url"`
+	Depth    int    `json:"depth"` // 0 for the top level
+	// Leaf has no subcategories or is at the depth limit of the crawl
+	Leaf bool `json:"leaf"`
 }
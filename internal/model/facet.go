@@ -0,0 +1,13 @@
+package model
+
+// Facet is a filter of the catalog sidebar like brand, color or size.
+type Facet struct {
+	Name   string        `json:"name"`
+	Values []*FacetValue `json:"values"`
+}
+
+type FacetValue struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"` // products with the value, 0 when not shown
+	Url   string `json:"url"`   // catalog url with the value selected, empty when unknown
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Key identifies the product across runs and regions: the marketplace id or
+// the url for cards without a recognized id.
+func (p *ProductCard) Key() string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return p.Url
+}
+
+// Number parses a numeric field of the card like price, rate or reviews,
+// decimal comma included. ok is false for empty and non-numeric values.
+func Number(value string) (float64, bool) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
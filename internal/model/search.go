@@ -0,0 +1,9 @@
+package model
+
+type SearchQuery struct {
+	Text     string
+	Sort     string
+	PriceMin int
+	PriceMax int
+	Filters  map[string]string
+}
@@ -0,0 +1,59 @@
+package regions
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes the wide table with the price and availability columns of
+// every region followed by the price spread among the regions.
+func WriteCSV(w io.Writer, results []*Result) error {
+	header := []string{"id", "title", "url"}
+	for _, result := range results {
+		header = append(header, "price_"+result.Region, "available_"+result.Region)
+	}
+	header = append(header, "min_price", "max_price", "spread")
+
+	csvw := csv.NewWriter(w)
+	if err := csvw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range Table(results) {
+		record := []string{row.ID, row.Title, row.Url}
+		for i := range results {
+			record = append(record, formatNumber(row.Prices[i]), strconv.FormatBool(row.Available[i]))
+		}
+		low, high, spread := row.Spread()
+		record = append(record, formatNumber(low), formatNumber(high), formatNumber(spread))
+		if err := csvw.Write(record); err != nil {
+			return err
+		}
+	}
+	csvw.Flush()
+	return csvw.Error()
+}
+
+// WriteResults writes the wide table into the output directory.
+func WriteResults(output string, marketplace string, results []*Result) error {
+	if err := os.MkdirAll(output, os.ModePerm); err != nil {
+		return err
+	}
+	filepath := fmt.Sprintf("%s/%s-regions-%s.csv", output, marketplace, time.Now().Format("2006-01-02_15-04-05"))
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteCSV(f, results)
+}
+
+func formatNumber(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
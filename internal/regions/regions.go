@@ -0,0 +1,123 @@
+package regions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"wb-parser/internal/model"
+	"wb-parser/internal/service"
+)
+
+// Result is a crawl of the category in a single region.
+type Result struct {
+	Region   string
+	Products []*model.ProductCard
+}
+
+// Collect parses url once for every region with the service of the
+// marketplace built from opts. Every region is a regular Parse: its csv is
+// written to output and its products go to the sinks of opts.
+func Collect(ctx context.Context, marketplace string, regions []string, url string, pages int, output string, opts []service.Option) ([]*Result, error) {
+	results := []*Result{}
+	var errs []error
+	for _, region := range regions {
+		fmt.Println("Region:", region)
+		rec := &recorder{}
+		regionOpts := []service.Option{service.WithSink(rec)}
+		regionOpts = append(regionOpts, opts...)
+		regionOpts = append(regionOpts, service.WithRegion(region))
+		s, err := service.NewCatalogService(marketplace, regionOpts...)
+		if err != nil {
+			return nil, err
+		}
+		err = s.Parse(ctx, url, pages, output)
+		results = append(results, &Result{Region: region, Products: rec.products})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// recorder is a sink keeping the products of the crawl. Failed crawls are
+// not saved, so their region is left empty in the table.
+type recorder struct {
+	products []*model.ProductCard
+}
+
+func (r *recorder) Save(ctx context.Context, marketplace string, url string, region string, products []*model.ProductCard) error {
+	r.products = products
+	return nil
+}
+
+// Row is a product with its price in every region, nil when the product is
+// not available there.
+type Row struct {
+	ID     string
+	Title  string
+	Url    string
+	Prices []*float64
+	// Available is false for the regions where the product is not listed
+	Available []bool
+}
+
+// Spread returns the lowest and the highest price among the regions and the
+// difference between them in percent of the lowest.
+func (r *Row) Spread() (low *float64, high *float64, spread *float64) {
+	for _, price := range r.Prices {
+		if price == nil {
+			continue
+		}
+		if low == nil || *price < *low {
+			low = price
+		}
+		if high == nil || *price > *high {
+			high = price
+		}
+	}
+	if low != nil && *low != 0 {
+		v := math.Round((*high-*low) / *low * 10000) / 100
+		spread = &v
+	}
+	return low, high, spread
+}
+
+// Table joins the results by product, rows follow the order the products are
+// first seen in.
+func Table(results []*Result) []*Row {
+	rows := []*Row{}
+	index := map[string]*Row{}
+	for i, result := range results {
+		for _, product := range result.Products {
+			row, ok := index[product.Key()]
+			if !ok {
+				row = &Row{
+					ID:        product.ID,
+					Title:     product.Title,
+					Url:       product.Url,
+					Prices:    make([]*float64, len(results)),
+					Available: make([]bool, len(results)),
+				}
+				index[product.Key()] = row
+				rows = append(rows, row)
+			}
+			row.Available[i] = true
+			if row.Prices[i] == nil {
+				row.Prices[i] = number(product.Price)
+			}
+		}
+	}
+	return rows
+}
+
+func number(value string) *float64 {
+	f, ok := model.Number(value)
+	if !ok {
+		return nil
+	}
+	return &f
+}
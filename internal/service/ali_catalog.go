@@ -3,136 +3,166 @@ package service
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"wb-parser/internal/model"
-	chromedputils "wb-parser/package/chromedp_utils"
+	"wb-parser/package/money"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/runtime"
-	"github.com/chromedp/chromedp"
 )
 
-type aliCatalgService struct{}
+type aliCatalgService struct {
+	options
+}
+
+func NewAliCatalogService(opts ...Option) *aliCatalgService {
+	return &aliCatalgService{options: newOptions(opts)}
+}
 
-func NewAliCatalogService() *aliCatalgService {
-	return &aliCatalgService{}
+func (s *aliCatalgService) Parse(ctx context.Context, url string, pages int, output string) error {
+	// products collected before an error are still written
+	products, err := s.Collect(ctx, url, pages)
+	return errors.Join(
+		err,
+		s.writeResults(ctx, products, output),
+		s.save(ctx, MarketplaceAli, url, products, err),
+	)
 }
 
-func (s *aliCatalgService) Parse(ctx context.Context, url string, pages int, output string) {
-	cctx, cancel := chromedputils.InitChromeDPContext(ctx)
-	defer cancel()
-	products, err := s.parseCatalog(cctx, url, pages)
+func (s *aliCatalgService) Collect(ctx context.Context, url string, pages int) ([]*model.ProductCard, error) {
+	b, err := s.startBrowser(ctx, MarketplaceAli, s.regionSetup(s.setRegion))
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	if err := s.writeResults(ctx, products, output); err != nil {
-		fmt.Println(err)
+	defer b.close()
+	if s.facetSplit != "" {
+		return s.crawlFacet(b, url, pages, aliFacets, nil, s.parseCatalog)
 	}
+	return s.parseCatalog(b, url, pages)
 }
 
-func (s *aliCatalgService) parseCatalog(ctx context.Context, url string, pages int) ([]*model.ProductCard, error) {
+func (s *aliCatalgService) parseCatalog(b *browser, url string, pages int) ([]*model.ProductCard, error) {
 	products := []*model.ProductCard{}
+	position := 0
+	listing := s.listing(url)
+	selector := aliCardSelector
+	if listing.kind == ListingSeller {
+		selector = aliStoreCardSelector
+		if !strings.Contains(url, "all-items") {
+			url = s.storeItemsUrl(listing.id)
+		}
+	}
 	for i := 1; i < pages; i++ {
 		pageUrl := s.generatePageUrl(url, i)
 
 		// Navigate
-		if err := chromedp.Run(ctx, chromedp.Navigate(pageUrl)); err != nil {
-			return nil, err
+		if err := s.open(b, pageUrl); err != nil {
+			if stopCrawl(b.parent, err) {
+				return products, err
+			}
+			fmt.Println(err)
+			continue
 		}
 
-		parsedProducts, err := s.parseProducts(ctx)
+		parsedProducts, cards, err := s.parseProducts(b.ctx, selector, i, position)
+		listing.tag(parsedProducts)
+		observePage(MarketplaceAli, parsedProducts, cards)
+		s.progress(i, parsedProducts)
 		if err != nil {
 			continue
 		}
+		position += cards
 		products = append(products, parsedProducts...)
 	}
 
 	return products, nil
 }
 
-func (s *aliCatalgService) parseProducts(ctx context.Context) ([]*model.ProductCard, error) {
+func (s *aliCatalgService) parseProducts(ctx context.Context, selector string, page int, offset int) ([]*model.ProductCard, int, error) {
 	var productNodes []*cdp.Node
-	productCardClass := ".product-snippet_ProductSnippet__content__1mogfw"
-	if err := chromedp.Run(
-		ctx,
-		chromedp.WaitVisible(productCardClass, chromedp.ByQueryAll),
-		chromedp.Nodes(productCardClass, &productNodes, chromedp.ByQueryAll),
-	); err != nil {
-		return nil, err
+	if err := s.loadCards(ctx, selector, &productNodes); err != nil {
+		return nil, 0, err
 	}
-
-	for {
-		l := len(productNodes)
-		chromedp.Run(ctx,
-			chromedp.ActionFunc(func(ctx context.Context) error {
-				_, exp, err := runtime.Evaluate(`window.scrollTo(0,document.body.scrollHeight);`).Do(ctx)
-				time.Sleep(500 * time.Millisecond)
-				if err != nil {
-					return err
-				}
-				if exp != nil {
-					return exp
-				}
-				return nil
-			}),
-			chromedp.WaitVisible(productCardClass, chromedp.ByQueryAll),
-			chromedp.Nodes(productCardClass, &productNodes, chromedp.ByQueryAll),
-		)
-		if l == len(productNodes) {
-			break
-		}
+	cards, err := s.extractCards(ctx, selector, aliExtractScript)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	fmt.Println(len(productNodes))
+	fmt.Println(len(cards))
 	products := []*model.ProductCard{}
 
-	// TODO parse products
-	for _, node := range productNodes {
-		var productTitle string
-		var linkNodes []*cdp.Node
-		var url string
-		var price string
-		var rate string
-		var reviews string // кол-во покупок
-
-		if err := chromedp.Run(ctx,
-			chromedputils.RunWithTimeOut(ctx, 500*time.Millisecond, chromedp.Tasks{
-				chromedp.Text(".product-snippet_ProductSnippet__name__1mogfw", &productTitle, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				chromedp.Text(".snow-price_SnowPrice__mainM__uw8t09", &price, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				chromedp.Nodes(".product-snippet_ProductSnippet__galleryBlock__1mogfw", &linkNodes, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				// chromedp.Text("", &rate, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				// chromedp.Text("", &reviews, chromedp.ByQueryAll)
-			}),
-		); err != nil {
+	for _, card := range cards {
+		if card.Url == "" {
 			continue
 		}
-		chromedp.Run(ctx,
-			chromedputils.RunWithTimeOut(ctx, 100*time.Millisecond, chromedp.Tasks{
-				chromedp.Text(".product-snippet_ProductSnippet__score__1mogfw", &rate, chromedp.ByQueryAll, chromedp.FromNode(node)),
-			}),
-		)
-		chromedp.Run(ctx,
-			chromedputils.RunWithTimeOut(ctx, 100*time.Millisecond, chromedp.Tasks{
-				chromedp.Text(".product-snippet_ProductSnippet__sold__1mogfw", &reviews, chromedp.ByQueryAll, chromedp.FromNode(node)),
-			}),
-		)
-		url = linkNodes[0].AttributeValue("href")
-
-		fmt.Println(productTitle, url, price)
-		products = append(products, &model.ProductCard{
-			Url:       url,
-			Title:     productTitle,
-			FullPrice: price,
-			Price:     price,
-			Rate:      rate,
-			Reviews:   reviews,
-		})
+		fmt.Println(card.Title, card.Url, card.Price)
+		price, fullPrice, currency := s.preparePrices(card)
+		product := &model.ProductCard{
+			ID:         s.productID(card.Url),
+			Url:        card.Url,
+			Title:      card.Title,
+			Image:      card.Image,
+			FullPrice:  fullPrice,
+			Price:      price,
+			Currency:   currency,
+			Rate:       card.Rate,
+			Reviews:    card.Reviews, // кол-во покупок
+			Page:       page,
+			Position:   offset + card.Index + 1,
+			Promoted:   card.Promoted,
+			Labels:     promoLabels(card.Labels),
+			Incomplete: !card.complete(),
+			Region:     s.region,
+		}
+		product.Discount = discountPercent(card.Discount, product.Price, product.FullPrice)
+		product.CardPrice = specialPrice(card.CardPrice)
+		product.Delivery = strings.Join(strings.Fields(card.Delivery), " ")
+		product.DeliveryDate, product.DeliveryDays = parseDelivery(card.Delivery, time.Now())
+		products = append(products, product)
 	}
 
-	return products, nil
+	return products, len(cards), nil
+}
+
+// preparePrices parses the current and the original price of the card and
+// converts them to the target currency when it is set. The raw text is kept
+// when it can not be parsed.
+func (s *aliCatalgService) preparePrices(card *rawCard) (string, string, string) {
+	price, err := money.Parse(strings.Split(card.Price, "\n")[0])
+	if err != nil {
+		return card.Price, card.Price, ""
+	}
+	fullPrice := price
+	if card.FullPrice != "" {
+		if full, err := money.Parse(card.FullPrice); err == nil && full.Value >= price.Value {
+			fullPrice = full
+			if fullPrice.Currency == "" {
+				fullPrice.Currency = price.Currency
+			}
+		}
+	}
+	if converted, err := s.convert(price); err != nil {
+		fmt.Println(err)
+	} else {
+		price = converted
+		fullPrice, _ = s.convert(fullPrice)
+	}
+	return price.String(), fullPrice.String(), price.Currency
+}
+
+var aliProductIDPattern = regexp.MustCompile(`/item/(\d+)\.html`)
+
+func (s *aliCatalgService) productID(url string) string {
+	if match := aliProductIDPattern.FindStringSubmatch(url); match != nil {
+		return match[1]
+	}
+	return ""
 }
 
 func (s *aliCatalgService) writeResults(ctx context.Context, products []*model.ProductCard, output string) error {
@@ -149,19 +179,38 @@ func (s *aliCatalgService) writeResults(ctx context.Context, products []*model.P
 	csvw := csv.NewWriter(f)
 
 	if err := csvw.Write([]string{
-		"title", "url", "price", "full_price", "rate", "reviews",
+		"id", "title", "url", "image", "price", "full_price", "rate", "reviews", "page", "position", "promoted", "incomplete", "currency", "region",
+		"discount", "card_price", "labels", "delivery", "delivery_date", "delivery_days", "fulfilment",
+		"brand", "seller",
 	}); err != nil {
 		return err
 	}
 
 	for _, product := range products {
 		row := []string{
+			product.ID,
 			product.Title,
 			product.Url,
+			product.Image,
 			product.Price,
 			product.FullPrice,
 			product.Rate,
 			product.Reviews,
+			strconv.Itoa(product.Page),
+			strconv.Itoa(product.Position),
+			strconv.FormatBool(product.Promoted),
+			strconv.FormatBool(product.Incomplete),
+			product.Currency,
+			product.Region,
+			product.Discount,
+			product.CardPrice,
+			strings.Join(product.Labels, "; "),
+			product.Delivery,
+			product.DeliveryDate,
+			product.DeliveryDays,
+			product.Fulfilment,
+			product.Brand,
+			product.Seller,
 		}
 		if err := csvw.Write(row); err != nil {
 			return err
@@ -181,3 +230,28 @@ func (s *aliCatalgService) generatePageUrl(url string, page int) string {
 		return fmt.Sprintf("%s?page=%d", url, page)
 	}
 }
+
+// aliSorting maps the sort orders to the SortType parameter of the search.
+// The search has no rating order, SortRating is not supported.
+var aliSorting = map[string]string{
+	SortPopular:   "total_tranpro_desc",
+	SortPriceAsc:  "price_asc",
+	SortPriceDesc: "price_desc",
+	SortNew:       "create_desc",
+}
+
+func (s *aliCatalgService) SearchURL(q *model.SearchQuery) string {
+	params := url.Values{}
+	params.Set("SearchText", q.Text)
+	params.Set("g", "y")
+	if v, ok := aliSorting[q.Sort]; ok {
+		params.Set("SortType", v)
+	}
+	if q.PriceMin > 0 {
+		params.Set("minPrice", fmt.Sprint(q.PriceMin))
+	}
+	if q.PriceMax > 0 {
+		params.Set("maxPrice", fmt.Sprint(q.PriceMax))
+	}
+	return buildSearchURL("https://aliexpress.ru/wholesale", params, q)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"wb-parser/internal/metrics"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Reactions on a captcha or anti-bot page.
+const (
+	BlockFail    = "fail"    // stop the crawl with BlockedError
+	BlockBackoff = "backoff" // pause and navigate again
+	BlockRotate  = "rotate"  // restart Chrome with the next proxy and a temporary profile, without -profile and imported cookies
+	BlockWait    = "wait"    // wait for a human to solve the captcha in the browser window
+)
+
+// BlockedError is returned when the marketplace serves a captcha, anti-bot
+// challenge or "access denied" page instead of the catalog.
+type BlockedError struct {
+	Marketplace string
+	Url         string
+	Reason      string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s blocked %s: %s", e.Marketplace, e.Url, e.Reason)
+}
+
+type BlockPolicy struct {
+	Action   string
+	Attempts int           // max reactions on a single page
+	Backoff  time.Duration // initial pause, doubled on every attempt
+	Proxies  []string      // rotated by BlockRotate
+	Timeout  time.Duration // max time to wait for a human
+}
+
+// blockSignature describes the challenge page of a marketplace. Pages are
+// not matched by their text: product titles like "Punisher" or everyday
+// phrases in the listing would be taken for a block.
+type blockSignature struct {
+	titles    []string // substrings of the lower case page title
+	urls      []string // substrings of the url the challenge is served on
+	selectors []string // elements present only on the challenge page
+}
+
+var blockSignatures = map[string]blockSignature{
+	MarketplaceWB: {
+		titles:    []string{"почти готово", "подозрительная активность"},
+		urls:      []string{"/security/", "/captcha"},
+		selectors: []string{"#challenge-form", ".captcha__image", "img[src*='captcha']"},
+	},
+	MarketplaceOzon: {
+		titles:    []string{"доступ ограничен", "antibot challenge"},
+		urls:      []string{"/abt/", "/captcha"},
+		selectors: []string{"#challenge", "form[action*='/abt/']", "[data-widget='captcha']"},
+	},
+	MarketplaceAli: {
+		titles:    []string{"captcha interception"},
+		urls:      []string{"/punish", "_____tmd_____", "x5secdata"},
+		selectors: []string{"#nc_1_n1z", ".nc-container", "#baxia-dialog-content", "iframe[src*='punish']"},
+	},
+}
+
+var commonBlockSignature = blockSignature{
+	titles: []string{"access denied", "доступ запрещен", "доступ запрещён", "too many requests", "just a moment"},
+	selectors: []string{
+		"iframe[src*='captcha']",
+		".g-recaptcha",
+		".h-captcha",
+		"#cf-challenge-running",
+		"#challenge-running",
+	},
+}
+
+// detectBlock matches the page title, url and challenge elements against the
+// block signatures of the marketplace.
+func detectBlock(ctx context.Context, marketplace string) (string, bool) {
+	signature := blockSignatures[marketplace]
+	selectors, err := json.Marshal(append(append([]string{}, signature.selectors...), commonBlockSignature.selectors...))
+	if err != nil {
+		return "", false
+	}
+	var title, location, element string
+	if err := chromedp.Run(ctx,
+		chromedputils.RunWithTimeOut(ctx, 2*time.Second, chromedp.Tasks{
+			chromedp.Title(&title),
+			chromedp.Location(&location),
+			chromedp.Evaluate(fmt.Sprintf(`%s.find((s) => document.querySelector(s) !== null) || ""`, selectors), &element),
+		}),
+	); err != nil {
+		return "", false
+	}
+	if element != "" {
+		return element, true
+	}
+	title = strings.ToLower(title)
+	for _, marker := range append(append([]string{}, signature.titles...), commonBlockSignature.titles...) {
+		if strings.Contains(title, marker) {
+			return "title " + marker, true
+		}
+	}
+	location = strings.ToLower(location)
+	for _, marker := range signature.urls {
+		if strings.Contains(location, marker) {
+			return "url " + marker, true
+		}
+	}
+	return "", false
+}
+
+// open navigates to the page and reacts on block pages according to the
+// block policy.
+func (o *options) open(b *browser, pageUrl string) error {
+	policy := o.blockPolicy
+	for attempt := 0; ; attempt++ {
+		if err := o.retry.Do(b.ctx, func(ctx context.Context) error {
+			if err := o.wait(ctx, b.marketplace); err != nil {
+				return err
+			}
+			return navigate(ctx, b.marketplace, pageUrl)
+		}); err != nil {
+			return err
+		}
+		reason, blocked := detectBlock(b.ctx, b.marketplace)
+		if !blocked {
+			return nil
+		}
+		metrics.CaptchaHits.WithLabelValues(b.marketplace).Inc()
+		blockedErr := &BlockedError{Marketplace: b.marketplace, Url: pageUrl, Reason: reason}
+		fmt.Println(blockedErr)
+		if policy.Action == BlockFail || attempt >= policy.Attempts {
+			return blockedErr
+		}
+
+		switch policy.Action {
+		case BlockBackoff:
+			if err := sleep(b.parent, policy.Backoff<<attempt); err != nil {
+				return err
+			}
+		case BlockRotate:
+			if err := b.restart(); err != nil {
+				return err
+			}
+		case BlockWait:
+			fmt.Println("solve the captcha in the browser window to continue")
+			if waitSolved(b, policy.Timeout) {
+				return nil
+			}
+			return blockedErr
+		default:
+			return blockedErr
+		}
+	}
+}
+
+func waitSolved(b *browser, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if err := sleep(b.parent, 2*time.Second); err != nil {
+			return false
+		}
+		if _, blocked := detectBlock(b.ctx, b.marketplace); !blocked {
+			return true
+		}
+	}
+	return false
+}
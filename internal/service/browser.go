@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wb-parser/internal/metrics"
+	"wb-parser/internal/model"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// browser is a Chrome instance of the crawl which can be restarted with
+// another proxy when the marketplace blocks it.
+type browser struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	parent      context.Context
+	marketplace string
+	proxies     []string
+	proxy       int
+	profile     string
+	cookies     cookieFiles
+	// rotated is set once the blocked Chrome was replaced by a fresh one
+	// without the profile and the imported cookies
+	rotated bool
+	// setup prepares every started Chrome before the crawl, may be nil
+	setup func(ctx context.Context) error
+}
+
+// cookieFiles are loaded into the started Chrome and written on close,
+// unless the browser was rotated.
+type cookieFiles struct {
+	load string
+	save string
+}
+
+func (o *options) startBrowser(ctx context.Context, marketplace string, setup func(ctx context.Context) error) (*browser, error) {
+	b := &browser{
+		parent:      ctx,
+		marketplace: marketplace,
+		proxies:     o.blockPolicy.Proxies,
+		profile:     o.profile,
+		cookies:     o.cookies,
+		setup:       setup,
+	}
+	if err := b.start(); err != nil {
+		b.close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *browser) start() error {
+	opts := []chromedp.ExecAllocatorOption{}
+	if len(b.proxies) > 0 {
+		opts = append(opts, chromedp.ProxyServer(b.proxies[b.proxy%len(b.proxies)]))
+	}
+	// a rotated Chrome gets a temporary user-data-dir, the profile keeps
+	// the session the marketplace blocked
+	if b.profile != "" && !b.rotated {
+		opts = append(opts, chromedputils.WithProfile(b.profile))
+	}
+	cctx, cancel := chromedputils.InitChromeDPContext(b.parent, opts...)
+	b.ctx = chromedputils.WithTimeoutHook(cctx, func() {
+		metrics.Timeouts.WithLabelValues(b.marketplace).Inc()
+	})
+	b.cancel = cancel
+	if b.cookies.load != "" && !b.rotated {
+		cookies, err := chromedputils.LoadCookies(b.cookies.load)
+		if err != nil {
+			return err
+		}
+		if err := chromedputils.SetCookies(b.ctx, cookies); err != nil {
+			return err
+		}
+	}
+	if b.setup != nil {
+		return b.setup(b.ctx)
+	}
+	return nil
+}
+
+func (b *browser) restart() error {
+	metrics.ChromeRestarts.WithLabelValues(b.marketplace).Inc()
+	b.cancel()
+	b.proxy++
+	b.rotated = true
+	return b.start()
+}
+
+func (b *browser) close() {
+	if b.cookies.save != "" && b.rotated {
+		fmt.Println("cookies are not exported: the session was blocked and rotated")
+	}
+	if b.cookies.save != "" && !b.rotated {
+		if err := b.saveCookies(); err != nil {
+			fmt.Println(err)
+		}
+	}
+	b.cancel()
+}
+
+func (b *browser) saveCookies() error {
+	cookies, err := chromedputils.GetCookies(b.ctx)
+	if err != nil {
+		return err
+	}
+	return chromedputils.SaveCookies(b.cookies.save, cookies)
+}
+
+func navigate(ctx context.Context, marketplace string, pageUrl string) error {
+	start := time.Now()
+	err := chromedp.Run(ctx, chromedp.Navigate(pageUrl))
+	metrics.NavigationSeconds.WithLabelValues(marketplace).Observe(time.Since(start).Seconds())
+	if err == nil {
+		metrics.PagesFetched.WithLabelValues(marketplace).Inc()
+	}
+	return err
+}
+
+func observePage(marketplace string, products []*model.ProductCard, cards int) {
+	metrics.CardsFound.WithLabelValues(marketplace).Add(float64(cards))
+	for _, product := range products {
+		metrics.ObserveProduct(marketplace, product)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// loadCards waits for the first cards, scrolls the listing to the end and
+// retrieves all loaded cards.
+func (o *options) loadCards(ctx context.Context, selector string, nodes *[]*cdp.Node) error {
+	if err := o.waitNodes(ctx, selector, nodes); err != nil {
+		return err
+	}
+	res, err := chromedputils.ScrollToEnd(ctx, chromedputils.DefaultScrollOptions(selector))
+	fmt.Println(res)
+	if err != nil {
+		// the cards loaded before the error are still parsed
+		fmt.Println(err)
+	}
+	return o.waitNodes(ctx, selector, nodes)
+}
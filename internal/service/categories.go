@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"wb-parser/internal/model"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/chromedp"
+)
+
+// wbMenuUrl is the catalog menu JSON the WB site builds its menu from.
+const wbMenuUrl = "https://static-basket-01.wbbasket.ru/vol0/data/main-menu-ru-ru-v3.json"
+
+type wbMenuItem struct {
+	ID     int64        `json:"id"`
+	Name   string       `json:"name"`
+	Url    string       `json:"url"`
+	Childs []wbMenuItem `json:"childs"`
+}
+
+// Categories reads the whole WB tree from the catalog menu JSON, depth limits
+// the levels returned.
+func (s *wbCatalogService) Categories(ctx context.Context, depth int) ([]*model.Category, error) {
+	b, err := s.startBrowser(ctx, MarketplaceWB, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+
+	if err := s.open(b, wbMenuUrl); err != nil {
+		return nil, err
+	}
+	var body string
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(`document.body.innerText`, &body)); err != nil {
+		return nil, err
+	}
+	var menu []wbMenuItem
+	if err := json.Unmarshal([]byte(body), &menu); err != nil {
+		return nil, fmt.Errorf("wb catalog menu: %w", err)
+	}
+
+	categories := []*model.Category{}
+	var walk func(items []wbMenuItem, parent string, level int)
+	walk = func(items []wbMenuItem, parent string, level int) {
+		for _, item := range items {
+			category := &model.Category{
+				ID:       strconv.FormatInt(item.ID, 10),
+				ParentID: parent,
+				Name:     item.Name,
+				Url:      absoluteUrl("https://www.wildberries.ru", item.Url),
+				Depth:    level,
+				Leaf:     len(item.Childs) == 0 || atDepth(level, depth),
+			}
+			categories = append(categories, category)
+			if !category.Leaf {
+				walk(item.Childs, category.ID, level+1)
+			}
+		}
+	}
+	walk(menu, "", 0)
+	return categories, nil
+}
+
+var ozonCategoryIDPattern = regexp.MustCompile(`/category/(?:[^/?]*-)?(\d+)(?:[/?]|$)`)
+
+// Categories opens the catalog menu of the home page for the top level and
+// visits every category page for its subcategories down to depth levels.
+func (s *ozonCatalogService) Categories(ctx context.Context, depth int) ([]*model.Category, error) {
+	b, err := s.startBrowser(ctx, MarketplaceOzon, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+
+	openMenu := chromedputils.RunWithTimeOut(b.ctx, waitTimeout, chromedp.Tasks{
+		chromedp.Click(`[data-widget="catalogMenu"] button`, chromedp.ByQuery),
+		chromedp.WaitVisible(`[data-widget="catalogMenu"] a[href*="/category/"]`, chromedp.ByQuery),
+	})
+	return s.crawlCategories(b, categoryCrawl{
+		root:     "https://www.ozon.ru/",
+		base:     "https://www.ozon.ru",
+		openMenu: openMenu,
+		script:   ozonCategoriesScript,
+		pattern:  ozonCategoryIDPattern,
+		depth:    depth,
+	})
+}
+
+var aliCategoryIDPattern = regexp.MustCompile(`/category/(\d+)`)
+
+// Categories reads the top level from the home page menu and visits every
+// category page for its subcategories down to depth levels.
+func (s *aliCatalgService) Categories(ctx context.Context, depth int) ([]*model.Category, error) {
+	b, err := s.startBrowser(ctx, MarketplaceAli, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+
+	return s.crawlCategories(b, categoryCrawl{
+		root:    "https://aliexpress.ru/",
+		base:    "https://aliexpress.ru",
+		script:  aliCategoriesScript,
+		pattern: aliCategoryIDPattern,
+		depth:   depth,
+	})
+}
+
+// categoryCrawl describes the category pages of a marketplace.
+type categoryCrawl struct {
+	root     string
+	base     string
+	openMenu chromedp.Action // shows the top level menu on the root page, may be nil
+	script   string          // returns [{name, url}] of the subcategories of the page
+	pattern  *regexp.Regexp  // category id in the url
+	depth    int
+}
+
+type categoryLink struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+// crawlCategories walks the tree breadth first. Categories are deduplicated by
+// id, a category reachable from several parents keeps the first one.
+func (o *options) crawlCategories(b *browser, crawl categoryCrawl) ([]*model.Category, error) {
+	categories := []*model.Category{}
+	seen := map[string]bool{}
+
+	add := func(links []categoryLink, parent *model.Category) []*model.Category {
+		level := 0
+		parentID := ""
+		if parent != nil {
+			level = parent.Depth + 1
+			parentID = parent.ID
+		}
+		added := []*model.Category{}
+		for _, link := range links {
+			u := absoluteUrl(crawl.base, link.Url)
+			match := crawl.pattern.FindStringSubmatch(u)
+			if match == nil || seen[match[1]] {
+				continue
+			}
+			seen[match[1]] = true
+			category := &model.Category{
+				ID:       match[1],
+				ParentID: parentID,
+				Name:     strings.TrimSpace(link.Name),
+				Url:      u,
+				Depth:    level,
+				Leaf:     atDepth(level, crawl.depth),
+			}
+			categories = append(categories, category)
+			added = append(added, category)
+		}
+		return added
+	}
+
+	if err := o.open(b, crawl.root); err != nil {
+		return nil, err
+	}
+	if crawl.openMenu != nil {
+		if err := chromedp.Run(b.ctx, crawl.openMenu); err != nil {
+			return nil, fmt.Errorf("open catalog menu: %w", err)
+		}
+	}
+	links, err := o.categoryLinks(b.ctx, crawl.script)
+	if err != nil {
+		return nil, err
+	}
+	queue := add(links, nil)
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("no categories found on %s", crawl.root)
+	}
+
+	for len(queue) > 0 {
+		category := queue[0]
+		queue = queue[1:]
+		if category.Leaf {
+			continue
+		}
+		if err := o.open(b, category.Url); err != nil {
+			if stopCrawl(b.parent, err) {
+				return categories, err
+			}
+			fmt.Println(err)
+			continue
+		}
+		links, err := o.categoryLinks(b.ctx, crawl.script)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		children := add(links, category)
+		category.Leaf = len(children) == 0
+		fmt.Println(category.Name, len(children))
+		queue = append(queue, children...)
+	}
+	return categories, nil
+}
+
+func (o *options) categoryLinks(ctx context.Context, script string) ([]categoryLink, error) {
+	links := []categoryLink{}
+	err := o.retry.Do(ctx, func(ctx context.Context) error {
+		return chromedp.Run(ctx,
+			chromedputils.RunWithTimeOut(ctx, extractTimeout, chromedp.Tasks{
+				chromedp.Sleep(time.Second),
+				chromedp.Evaluate(script, &links),
+			}),
+		)
+	})
+	return links, err
+}
+
+// atDepth reports whether the level is the last one of the crawl, depth 0
+// is unlimited.
+func atDepth(level int, depth int) bool {
+	return depth > 0 && level+1 >= depth
+}
+
+// absoluteUrl resolves the link against the site and drops its query.
+func absoluteUrl(base string, link string) string {
+	u, err := url.Parse(resolveUrl(base, link))
+	if err != nil {
+		return link
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// resolveUrl resolves the link against the site.
+func resolveUrl(base string, link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	root, err := url.Parse(base)
+	if err != nil {
+		return link
+	}
+	return root.ResolveReference(u).String()
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fulfilment models of the product.
+const (
+	// FulfilmentFBO ships from the marketplace warehouse
+	FulfilmentFBO = "fbo"
+	// FulfilmentFBS ships from the seller warehouse
+	FulfilmentFBS = "fbs"
+)
+
+var months = map[string]time.Month{
+	"января": time.January, "февраля": time.February, "марта": time.March,
+	"апреля": time.April, "мая": time.May, "июня": time.June,
+	"июля": time.July, "августа": time.August, "сентября": time.September,
+	"октября": time.October, "ноября": time.November, "декабря": time.December,
+}
+
+var (
+	deliveryDatePattern  = regexp.MustCompile(`(\d{1,2})(?:\s*[-–—]\s*\d{1,2})?\s+(января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)`)
+	deliveryDaysPattern  = regexp.MustCompile(`(\d+)(?:\s*[-–—]\s*\d+)?\s*(?:дн|день|дня|дней|days?)`)
+	deliveryHoursPattern = regexp.MustCompile(`\d+(?:\s*[-–—]\s*\d+)?\s*(?:час|ч(?:$|[^\p{L}])|мин)`)
+)
+
+// parseDelivery normalizes the delivery text of the card like "Завтра",
+// "12–14 марта" or "через 3 дня" to the earliest expected date and the days
+// from now. Both are empty when the text has no date.
+func parseDelivery(text string, now time.Time) (string, string) {
+	text = strings.ToLower(text)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	days := -1
+	switch {
+	case text == "":
+		return "", ""
+	case strings.Contains(text, "сегодня") || deliveryHoursPattern.MatchString(text):
+		days = 0
+	case strings.Contains(text, "послезавтра"):
+		days = 2
+	case strings.Contains(text, "завтра"):
+		days = 1
+	}
+	if match := deliveryDatePattern.FindStringSubmatch(text); days < 0 && match != nil {
+		day, _ := strconv.Atoi(match[1])
+		date := time.Date(today.Year(), months[match[2]], day, 0, 0, 0, 0, today.Location())
+		if date.Before(today) {
+			// the date is in the next year, built anew so 29 February
+			// is not shifted by the current year
+			date = time.Date(today.Year()+1, months[match[2]], day, 0, 0, 0, 0, today.Location())
+		}
+		days = int(date.Sub(today).Hours()+12) / 24
+	}
+	if match := deliveryDaysPattern.FindStringSubmatch(text); days < 0 && match != nil {
+		days, _ = strconv.Atoi(match[1])
+	}
+	if days < 0 {
+		return "", ""
+	}
+	return today.AddDate(0, 0, days).Format("2006-01-02"), strconv.Itoa(days)
+}
+
+// fulfilment tells the warehouse by its mention on the card, it is empty
+// when the card mentions neither.
+func fulfilment(seller bool, warehouse bool) string {
+	switch {
+	case seller:
+		return FulfilmentFBS
+	case warehouse:
+		return FulfilmentFBO
+	}
+	return ""
+}
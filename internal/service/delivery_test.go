@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDelivery(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		text string
+		now  time.Time
+		date string
+		days string
+	}{
+		{text: "", now: now},
+		{text: "Бесплатно", now: now},
+		{text: "Сегодня", now: now, date: "2024-03-10", days: "0"},
+		{text: "Доставим за 2 ч", now: now, date: "2024-03-10", days: "0"},
+		{text: "2 ч", now: now, date: "2024-03-10", days: "0"},
+		{text: "за 30 мин", now: now, date: "2024-03-10", days: "0"},
+		{text: "2 чайника", now: now},
+		{text: "Завтра", now: now, date: "2024-03-11", days: "1"},
+		{text: "Послезавтра", now: now, date: "2024-03-12", days: "2"},
+		{text: "12–14 марта", now: now, date: "2024-03-12", days: "2"},
+		{text: "1 апреля", now: now, date: "2024-04-01", days: "22"},
+		{text: "через 3 дня", now: now, date: "2024-03-13", days: "3"},
+		{text: "5-7 дней", now: now, date: "2024-03-15", days: "5"},
+		// a date before today is in the next year
+		{text: "2 января", now: time.Date(2024, time.December, 30, 10, 0, 0, 0, time.UTC), date: "2025-01-02", days: "3"},
+		{text: "29 февраля", now: time.Date(2023, time.December, 31, 10, 0, 0, 0, time.UTC), date: "2024-02-29", days: "60"},
+	}
+	for _, tt := range tests {
+		date, days := parseDelivery(tt.text, tt.now)
+		if date != tt.date || days != tt.days {
+			t.Errorf("parseDelivery(%q) = %q, %q, want %q, %q", tt.text, date, days, tt.date, tt.days)
+		}
+	}
+}
+
+func TestFulfilment(t *testing.T) {
+	tests := []struct {
+		seller    bool
+		warehouse bool
+		want      string
+	}{
+		{want: ""},
+		{seller: true, want: FulfilmentFBS},
+		{warehouse: true, want: FulfilmentFBO},
+		{seller: true, warehouse: true, want: FulfilmentFBS},
+	}
+	for _, tt := range tests {
+		if got := fulfilment(tt.seller, tt.warehouse); got != tt.want {
+			t.Errorf("fulfilment(%v, %v) = %q, want %q", tt.seller, tt.warehouse, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Extraction scripts are functions of a card element and its index on the
+// page returning the card as rawCard JSON. extractCards applies them to all
+// cards of the page in a single evaluation.
+var (
+	//go:embed scripts/wb.js
+	wbExtractScript string
+	//go:embed scripts/ozon.js
+	ozonExtractScript string
+	//go:embed scripts/ali.js
+	aliExtractScript string
+)
+
+// Category scripts return the subcategory links of the page as
+// categoryLink JSON array.
+var (
+	//go:embed scripts/ozon_categories.js
+	ozonCategoriesScript string
+	//go:embed scripts/ali_categories.js
+	aliCategoriesScript string
+)
+
+// rawCard is a product card as it is shown on the page, the texts are
+// normalized by the marketplace service.
+type rawCard struct {
+	Index int    `json:"index"`
+	Url   string `json:"url"`
+	Title string `json:"title"`
+	Image string `json:"image"`
+	Price string `json:"price"`
+	// FullPrice is the original price when the script finds it apart
+	// from the current one
+	FullPrice string `json:"fullPrice"`
+	// CardPrice is the price with Ozon Card or WB Wallet
+	CardPrice string   `json:"cardPrice"`
+	Discount  string   `json:"discount"`
+	Labels    []string `json:"labels"`
+	Delivery  string   `json:"delivery"`
+	// Seller is set when the card mentions shipping from the seller warehouse
+	Seller bool `json:"seller"`
+	// Warehouse is set when the card mentions the marketplace warehouse
+	Warehouse bool   `json:"warehouse"`
+	Rate      string `json:"rate"`
+	Reviews   string `json:"reviews"`
+	Promoted  bool   `json:"promoted"`
+}
+
+// placeholderPattern matches file names of placeholder images like
+// placeholder.svg, img-stub.png or no_photo.jpg as separate words, so
+// product photos merely containing such letters are not flagged.
+var placeholderPattern = regexp.MustCompile(`(?i)(^|[^a-z])(placeholder|stub|loader|spinner|no[-_]?photo|no[-_]?image)([^a-z]|$)`)
+
+// complete reports whether lazy loaded content of the card is rendered:
+// a real image instead of a placeholder and a price instead of a skeleton.
+func (c *rawCard) complete() bool {
+	return realImage(c.Image) && strings.ContainsAny(c.Price, "0123456789")
+}
+
+func realImage(src string) bool {
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return false
+	}
+	name := src
+	if u, err := url.Parse(src); err == nil {
+		name = path.Base(u.Path)
+	}
+	return !placeholderPattern.MatchString(name)
+}
+
+const extractTimeout = 10 * time.Second
+
+// extractCards evaluates the extraction script for every card of the page
+// and waits for lazy loaded content of the incomplete cards.
+func (o *options) extractCards(ctx context.Context, selector string, script string) ([]*rawCard, error) {
+	sel, err := json.Marshal(selector)
+	if err != nil {
+		return nil, err
+	}
+	read := strings.TrimSpace(script)
+	call := fmt.Sprintf("Array.from(document.querySelectorAll(%s)).map(%s)", sel, read)
+
+	cards := []*rawCard{}
+	err = o.retry.Do(ctx, func(ctx context.Context) error {
+		return chromedp.Run(ctx,
+			chromedputils.RunWithTimeOut(ctx, extractTimeout, chromedp.Tasks{
+				chromedp.Evaluate(call, &cards),
+			}),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.resolveLazy(ctx, string(sel), read, cards)
+	return cards, nil
+}
+
+// findCardScript returns the first card element with a link to the url, so a
+// card is found again after scrolling added or removed cards before it.
+const findCardScript = `(selector, url) => Array.from(document.querySelectorAll(selector)).find(
+	(card) => Array.from(card.querySelectorAll("a[href]")).some((a) => a.getAttribute("href") === url)
+) || null`
+
+// resolveLazy scrolls every incomplete card into view and polls it until the
+// content is rendered. Each card gets at most lazyCardBudget and the whole
+// page lazyPageBudget, cards still incomplete after that are left as is.
+// sel is the JSON encoded card selector and read the extraction script, only
+// the polled card is read again. Cards keep their index on the first read.
+func (o *options) resolveLazy(ctx context.Context, sel string, read string, cards []*rawCard) {
+	pageDeadline := time.Now().Add(o.lazyPageBudget)
+	for i, card := range cards {
+		if card.complete() || card.Url == "" {
+			continue
+		}
+		cardDeadline := time.Now().Add(o.lazyCardBudget)
+		if cardDeadline.After(pageDeadline) {
+			cardDeadline = pageDeadline
+		}
+		if !time.Now().Before(cardDeadline) {
+			return
+		}
+
+		u, err := json.Marshal(card.Url)
+		if err != nil {
+			continue
+		}
+		find := fmt.Sprintf("(%s)(%s, %s)", findCardScript, sel, u)
+		scroll := fmt.Sprintf(`(() => { const card = %s; if (card) card.scrollIntoView({block: "center"}); })()`, find)
+		one := fmt.Sprintf(`(() => { const card = %s; return card ? (%s)(card, %d) : null; })()`, find, read, card.Index)
+		if err := chromedp.Run(ctx, chromedp.Evaluate(scroll, nil)); err != nil {
+			return
+		}
+		for time.Now().Before(cardDeadline) {
+			if err := sleep(ctx, 100*time.Millisecond); err != nil {
+				return
+			}
+			var updated *rawCard
+			if err := chromedp.Run(ctx, chromedp.Evaluate(one, &updated)); err != nil || updated == nil {
+				break
+			}
+			cards[i] = updated
+			if updated.complete() {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"wb-parser/internal/model"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/chromedp"
+)
+
+// facetsScript is a function of facetSelectors returning the sidebar filters
+// as rawFacet JSON array.
+//
+//go:embed scripts/facets.js
+var facetsScript string
+
+// facetSelectors locate the filters of the catalog sidebar.
+type facetSelectors struct {
+	Block string `json:"block"` // a single filter
+	Title string `json:"title"` // filter name inside the block
+	Value string `json:"value"` // filter option inside the block
+	Count string `json:"count"` // products count inside the option, may be empty
+}
+
+// facetConfig describes the sidebar of a marketplace.
+type facetConfig struct {
+	base      string
+	selectors facetSelectors
+}
+
+var (
+	wbFacets = facetConfig{
+		base: "https://www.wildberries.ru",
+		selectors: facetSelectors{
+			Block: ".filters-desktop .filter",
+			Title: ".filter__title",
+			Value: ".filter__item",
+			Count: ".checkbox-with-text__count",
+		},
+	}
+	ozonFacets = facetConfig{
+		base: "https://www.ozon.ru",
+		selectors: facetSelectors{
+			Block: `[data-widget="filtersDesktop"] > div > div`,
+			Title: "div:first-child > span",
+			Value: "a[href]",
+		},
+	}
+	aliFacets = facetConfig{
+		base: "https://aliexpress.ru",
+		selectors: facetSelectors{
+			Block: "[class*='SearchFilters'] [class*='FilterBlock']",
+			Title: "[class*='title']",
+			Value: "[class*='Checkbox'], a[href]",
+		},
+	}
+)
+
+type rawFacet struct {
+	Index  int             `json:"index"`
+	Name   string          `json:"name"`
+	Values []rawFacetValue `json:"values"`
+}
+
+type rawFacetValue struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Url   string `json:"url"`
+}
+
+// crawlFunc crawls pages of the listing url with the browser.
+type crawlFunc func(b *browser, url string, pages int) ([]*model.ProductCard, error)
+
+func (s *wbCatalogService) Facets(ctx context.Context, url string) ([]*model.Facet, error) {
+	b, err := s.startBrowser(ctx, MarketplaceWB, s.regionSetup(s.setRegion))
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+	return s.facets(b, url, wbFacets)
+}
+
+func (s *ozonCatalogService) Facets(ctx context.Context, url string) ([]*model.Facet, error) {
+	b, err := s.startBrowser(ctx, MarketplaceOzon, s.regionSetup(s.setRegion))
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+	return s.facets(b, url, ozonFacets)
+}
+
+func (s *aliCatalgService) Facets(ctx context.Context, url string) ([]*model.Facet, error) {
+	b, err := s.startBrowser(ctx, MarketplaceAli, s.regionSetup(s.setRegion))
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+	return s.facets(b, url, aliFacets)
+}
+
+// facets opens the catalog page and reads its sidebar filters.
+func (o *options) facets(b *browser, pageUrl string, config facetConfig) ([]*model.Facet, error) {
+	raw, err := o.rawFacets(b, pageUrl, config)
+	if err != nil {
+		return nil, err
+	}
+	facets := []*model.Facet{}
+	for _, f := range raw {
+		facet := &model.Facet{Name: f.Name}
+		for _, v := range f.Values {
+			facet.Values = append(facet.Values, &model.FacetValue{
+				Name:  v.Name,
+				Count: v.Count,
+				Url:   facetUrl(config.base, v.Url),
+			})
+		}
+		facets = append(facets, facet)
+	}
+	return facets, nil
+}
+
+func (o *options) rawFacets(b *browser, pageUrl string, config facetConfig) ([]*rawFacet, error) {
+	if err := o.open(b, pageUrl); err != nil {
+		return nil, err
+	}
+	selectors, err := json.Marshal(config.selectors)
+	if err != nil {
+		return nil, err
+	}
+	call := fmt.Sprintf("(%s)(%s)", strings.TrimSpace(facetsScript), selectors)
+
+	facets := []*rawFacet{}
+	err = o.retry.Do(b.ctx, func(ctx context.Context) error {
+		return chromedp.Run(ctx,
+			chromedputils.RunWithTimeOut(ctx, waitTimeout, chromedp.Tasks{
+				chromedp.WaitVisible(config.selectors.Block, chromedp.ByQuery),
+				chromedp.Evaluate(call, &facets),
+			}),
+		)
+	})
+	return facets, err
+}
+
+// crawlFacet splits the crawl of the catalog into a crawl per value of the
+// facet selected by WithFacetSplit, so every value gets its own page depth.
+// Products listed under several values are kept once. Products without a
+// value of the facet are not crawled, count reports them when the marketplace
+// shows the catalog total and the counts of the values; it may be nil.
+func (o *options) crawlFacet(b *browser, pageUrl string, pages int, config facetConfig, count countFunc, crawl crawlFunc) ([]*model.ProductCard, error) {
+	raw, err := o.rawFacets(b, pageUrl, config)
+	if err != nil {
+		if stopCrawl(b.parent, err) {
+			return nil, err
+		}
+		fmt.Println(err)
+	}
+	var facet *rawFacet
+	for _, f := range raw {
+		if strings.Contains(strings.ToLower(f.Name), strings.ToLower(o.facetSplit)) {
+			facet = f
+			break
+		}
+	}
+	if facet == nil {
+		fmt.Printf("facet %q not found, crawling the catalog as is\n", o.facetSplit)
+		return crawl(b, pageUrl, pages)
+	}
+	if count != nil {
+		if err := o.checkFacetCoverage(b, pageUrl, facet, count); err != nil {
+			if stopCrawl(b.parent, err) {
+				return nil, err
+			}
+			fmt.Println(err)
+		}
+	}
+
+	urls := []string{}
+	for _, value := range facet.Values {
+		u := facetUrl(config.base, value.Url)
+		if u == "" {
+			// options without links apply the filter by a click
+			if u, err = o.selectFacetValue(b, pageUrl, config, facet.Index, value.Index); err != nil {
+				if stopCrawl(b.parent, err) {
+					return nil, err
+				}
+				fmt.Println(value.Name, err)
+				continue
+			}
+		}
+		urls = append(urls, u)
+	}
+
+	products := []*model.ProductCard{}
+	var errs []error
+	for i, u := range urls {
+		fmt.Printf("%s %d/%d: %s\n", facet.Name, i+1, len(urls), u)
+		parsed, err := crawl(b, u, pages)
+		products = append(products, parsed...)
+		if err != nil {
+			errs = append(errs, err)
+			if stopCrawl(b.parent, err) {
+				break
+			}
+		}
+	}
+	return dedupe(products), errors.Join(errs...)
+}
+
+// checkFacetCoverage warns when the values of the facet list fewer products
+// than the catalog, e.g. products without a brand. Values may share products,
+// so a larger sum is fine. Facets showing no counts are not checked.
+func (o *options) checkFacetCoverage(b *browser, pageUrl string, facet *rawFacet, count countFunc) error {
+	sum := 0
+	for _, value := range facet.Values {
+		sum += value.Count
+	}
+	if sum == 0 {
+		return nil
+	}
+	total, err := count(b, pageUrl)
+	if err != nil {
+		return err
+	}
+	if sum < total {
+		fmt.Printf("values of facet %q list %d of %d products, the other %d products are not crawled\n", facet.Name, sum, total, total-sum)
+	}
+	return nil
+}
+
+// selectFacetValue clicks the option of the sidebar and returns the url of
+// the filtered catalog.
+func (o *options) selectFacetValue(b *browser, pageUrl string, config facetConfig, facet int, value int) (string, error) {
+	if err := o.open(b, pageUrl); err != nil {
+		return "", err
+	}
+	block, err := json.Marshal(config.selectors.Block)
+	if err != nil {
+		return "", err
+	}
+	option, err := json.Marshal(config.selectors.Value)
+	if err != nil {
+		return "", err
+	}
+	click := fmt.Sprintf(`(() => {
+		const block = document.querySelectorAll(%s)[%d];
+		const option = block && block.querySelectorAll(%s)[%d];
+		if (!option) return false;
+		option.click();
+		return true;
+	})()`, block, facet, option, value)
+
+	var before string
+	var clicked bool
+	if err := chromedp.Run(b.ctx,
+		chromedp.Location(&before),
+		chromedp.Evaluate(click, &clicked),
+	); err != nil {
+		return "", err
+	}
+	if !clicked {
+		return "", errors.New("facet option not found")
+	}
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		if err := sleep(b.ctx, 200*time.Millisecond); err != nil {
+			return "", err
+		}
+		var after string
+		if err := chromedp.Run(b.ctx, chromedp.Location(&after)); err != nil {
+			return "", err
+		}
+		if after != before {
+			return after, nil
+		}
+	}
+	return "", errors.New("catalog url did not change after selecting the facet option")
+}
+
+// facetUrl resolves the option link, empty links stay empty.
+func facetUrl(base string, link string) string {
+	if link == "" || strings.HasPrefix(link, "#") || strings.HasPrefix(link, "javascript:") {
+		return ""
+	}
+	return resolveUrl(base, link)
+}
+
+// dedupe keeps the first occurrence of every product.
+func dedupe(products []*model.ProductCard) []*model.ProductCard {
+	res := []*model.ProductCard{}
+	seen := map[string]bool{}
+	for _, product := range products {
+		key := product.Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, product)
+	}
+	return res
+}
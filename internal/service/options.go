@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"time"
+	"wb-parser/internal/model"
+	"wb-parser/package/money"
+	"wb-parser/package/ratelimit"
+	"wb-parser/package/retry"
+)
+
+// Sink receives products of every Parse call in addition to the csv file.
+type Sink interface {
+	// Save receives products of the url crawled in the delivery region, empty
+	// for the default one.
+	Save(ctx context.Context, marketplace string, url string, region string, products []*model.ProductCard) error
+}
+
+// ProgressFunc is called after every catalog page with the products parsed
+// from it.
+type ProgressFunc func(page int, products []*model.ProductCard)
+
+type options struct {
+	sinks       []Sink
+	onProgress  ProgressFunc
+	blockPolicy BlockPolicy
+	retry       retry.Policy
+	rateLimits  *ratelimit.Registry
+
+	lazyCardBudget time.Duration
+	lazyPageBudget time.Duration
+
+	region  string
+	profile string
+	cookies cookieFiles
+
+	currency string
+	rates    *money.Rates
+
+	facetSplit string
+	sliceLimit int
+}
+
+type Option func(*options)
+
+func WithSink(sink Sink) Option {
+	return func(o *options) {
+		o.sinks = append(o.sinks, sink)
+	}
+}
+
+func WithProgress(fn ProgressFunc) Option {
+	return func(o *options) {
+		o.onProgress = fn
+	}
+}
+
+func WithBlockPolicy(policy BlockPolicy) Option {
+	return func(o *options) {
+		o.blockPolicy = policy
+	}
+}
+
+// WithRetryPolicy sets retries of navigation, waiting for the cards and
+// their extraction.
+func WithRetryPolicy(policy retry.Policy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithRateLimits limits page requests of the service marketplace. The same
+// registry should be passed to all services to share the limits.
+func WithRateLimits(registry *ratelimit.Registry) Option {
+	return func(o *options) {
+		o.rateLimits = registry
+	}
+}
+
+// Default budgets of waiting for lazy loaded card content, see WithLazyBudget.
+const (
+	DefaultLazyCardBudget = time.Second
+	DefaultLazyPageBudget = 15 * time.Second
+)
+
+// WithLazyBudget limits waiting for lazy loaded images and prices of a
+// single card and of all cards of a page.
+func WithLazyBudget(card time.Duration, page time.Duration) Option {
+	return func(o *options) {
+		o.lazyCardBudget = card
+		o.lazyPageBudget = page
+	}
+}
+
+// WithRegion sets the delivery region before crawling, see setRegion of the
+// marketplace service for the supported values.
+func WithRegion(region string) Option {
+	return func(o *options) {
+		o.region = region
+	}
+}
+
+// WithProfile runs Chrome with the persistent user-data-dir, see
+// chromedputils.ProfileDir.
+func WithProfile(dir string) Option {
+	return func(o *options) {
+		o.profile = dir
+	}
+}
+
+// WithCookies loads cookies from the load file into the browser before the
+// crawl and writes the browser cookies to the save file after it. Either
+// path may be empty.
+func WithCookies(load string, save string) Option {
+	return func(o *options) {
+		o.cookies = cookieFiles{load: load, save: save}
+	}
+}
+
+// WithCurrency converts parsed prices to the target currency with rates.
+func WithCurrency(target string, rates *money.Rates) Option {
+	return func(o *options) {
+		o.currency = target
+		o.rates = rates
+	}
+}
+
+// WithFacetSplit crawls the catalog once per value of the sidebar facet with
+// the name, e.g. "Бренд", to get past the page depth limit of the listing.
+func WithFacetSplit(facet string) Option {
+	return func(o *options) {
+		o.facetSplit = facet
+	}
+}
+
+// WithPriceSlicing splits the catalog into price ranges listing at most limit
+// products each, so the page depth limit does not hide the rest. 0 disables it.
+func WithPriceSlicing(limit int) Option {
+	return func(o *options) {
+		o.sliceLimit = limit
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		blockPolicy: BlockPolicy{
+			Action:   BlockBackoff,
+			Attempts: 3,
+			Backoff:  30 * time.Second,
+			Timeout:  5 * time.Minute,
+		},
+		retry:          DefaultRetryPolicy(),
+		lazyCardBudget: DefaultLazyCardBudget,
+		lazyPageBudget: DefaultLazyPageBudget,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// save passes products of the crawl to the sinks. Failed and empty crawls are
+// skipped: compared with them the next run would report every product as new.
+func (o *options) save(ctx context.Context, marketplace string, url string, products []*model.ProductCard, crawlErr error) error {
+	if crawlErr != nil || len(products) == 0 {
+		return nil
+	}
+	for _, sink := range o.sinks {
+		if err := sink.Save(ctx, marketplace, url, o.region, products); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *options) progress(page int, products []*model.ProductCard) {
+	if o.onProgress != nil {
+		o.onProgress(page, products)
+	}
+}
+
+// convert returns the amount in the target currency, the amount as is when
+// it can not be converted.
+func (o *options) convert(amount money.Amount) (money.Amount, error) {
+	if o.currency == "" || o.rates == nil {
+		return amount, nil
+	}
+	return o.rates.Convert(amount, o.currency)
+}
+
+// wait blocks until the marketplace rate limit allows the next request.
+func (o *options) wait(ctx context.Context, marketplace string) error {
+	if o.rateLimits == nil {
+		return nil
+	}
+	if l := o.rateLimits.Get(marketplace); l != nil {
+		return l.Wait(ctx)
+	}
+	return nil
+}
@@ -3,36 +3,51 @@ package service
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"wb-parser/internal/model"
-	chromedputils "wb-parser/package/chromedp_utils"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/runtime"
-	"github.com/chromedp/chromedp"
 )
 
-type ozonCatalogService struct{}
+type ozonCatalogService struct {
+	options
+}
 
-func NewOzonCatalogService() *ozonCatalogService {
-	return &ozonCatalogService{}
+func NewOzonCatalogService(opts ...Option) *ozonCatalogService {
+	return &ozonCatalogService{options: newOptions(opts)}
 }
 
-func (s *ozonCatalogService) Parse(ctx context.Context, url string, pages int, output string) {
-	cctx, cancel := chromedputils.InitChromeDPContext(ctx)
-	defer cancel()
+func (s *ozonCatalogService) Parse(ctx context.Context, url string, pages int, output string) error {
+	// products collected before an error are still written
+	products, err := s.Collect(ctx, url, pages)
+	return errors.Join(
+		err,
+		s.writeResults(ctx, products, output),
+		s.save(ctx, MarketplaceOzon, url, products, err),
+	)
+}
 
-	products, err := s.parseCatalog(cctx, url, pages)
+func (s *ozonCatalogService) Collect(ctx context.Context, url string, pages int) ([]*model.ProductCard, error) {
+	b, err := s.startBrowser(ctx, MarketplaceOzon, s.regionSetup(s.setRegion))
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	if err := s.writeResults(ctx, products, output); err != nil {
-		fmt.Println(err)
+	defer b.close()
+
+	switch {
+	case s.facetSplit != "":
+		return s.crawlFacet(b, url, pages, ozonFacets, s.countProducts, s.parseCatalog)
+	case s.sliceLimit > 0:
+		return s.crawlPriceSlices(b, url, pages, s.countProducts, s.withPrice, s.priceRange, s.parseCatalog)
 	}
+	return s.parseCatalog(b, url, pages)
 }
 
 func (s *ozonCatalogService) writeResults(ctx context.Context, products []*model.ProductCard, output string) error {
@@ -49,19 +64,38 @@ func (s *ozonCatalogService) writeResults(ctx context.Context, products []*model
 	csvw := csv.NewWriter(f)
 
 	if err := csvw.Write([]string{
-		"title", "url", "price", "full_price", "rate", "reviews",
+		"id", "title", "url", "image", "price", "full_price", "rate", "reviews", "page", "position", "promoted", "incomplete", "currency", "region",
+		"discount", "card_price", "labels", "delivery", "delivery_date", "delivery_days", "fulfilment",
+		"brand", "seller",
 	}); err != nil {
 		return err
 	}
 
 	for _, product := range products {
 		row := []string{
+			product.ID,
 			product.Title,
 			product.Url,
+			product.Image,
 			product.Price,
 			product.FullPrice,
 			product.Rate,
 			product.Reviews,
+			strconv.Itoa(product.Page),
+			strconv.Itoa(product.Position),
+			strconv.FormatBool(product.Promoted),
+			strconv.FormatBool(product.Incomplete),
+			product.Currency,
+			product.Region,
+			product.Discount,
+			product.CardPrice,
+			strings.Join(product.Labels, "; "),
+			product.Delivery,
+			product.DeliveryDate,
+			product.DeliveryDays,
+			product.Fulfilment,
+			product.Brand,
+			product.Seller,
 		}
 		if err := csvw.Write(row); err != nil {
 			return err
@@ -74,7 +108,7 @@ func (s *ozonCatalogService) writeResults(ctx context.Context, products []*model
 	return nil
 }
 
-func (s *ozonCatalogService) parseCatalog(ctx context.Context, url string, pages int) ([]*model.ProductCard, error) {
+func (s *ozonCatalogService) parseCatalog(b *browser, url string, pages int) ([]*model.ProductCard, error) {
 	// // Navigate
 	// if err := chromedp.Run(ctx,
 	// 	chromedp.Navigate(url),
@@ -89,6 +123,8 @@ func (s *ozonCatalogService) parseCatalog(ctx context.Context, url string, pages
 	// totalPages := pages
 
 	products := []*model.ProductCard{}
+	position := 0
+	listing := s.listing(url)
 
 	for i := 1; i <= pages; i++ {
 		var pageUrl string
@@ -98,95 +134,76 @@ func (s *ozonCatalogService) parseCatalog(ctx context.Context, url string, pages
 		} else {
 			pageUrl = fmt.Sprintf("%s?page=%d", url, i)
 		}
-		if err := chromedp.Run(
-			ctx,
-			chromedp.Navigate(pageUrl),
-		); err != nil {
-			return nil, err
+		if err := s.open(b, pageUrl); err != nil {
+			if stopCrawl(b.parent, err) {
+				return products, err
+			}
+			fmt.Println(err)
+			continue
 		}
-		parsedProducts, err := s.parseProducts(ctx)
+		parsedProducts, cards, err := s.parseProducts(b.ctx, i, position)
+		listing.tag(parsedProducts)
+		observePage(MarketplaceOzon, parsedProducts, cards)
+		s.progress(i, parsedProducts)
 		if err != nil {
 			continue
 		}
+		position += cards
 		products = append(products, parsedProducts...)
 	}
 	return products, nil
 }
 
-func (s *ozonCatalogService) parseProducts(ctx context.Context) ([]*model.ProductCard, error) {
+func (s *ozonCatalogService) parseProducts(ctx context.Context, page int, offset int) ([]*model.ProductCard, int, error) {
 	var productNodes []*cdp.Node
 
-	if err := chromedp.Run(
-		ctx,
-		chromedp.WaitVisible(".tile-root", chromedp.ByQueryAll),
-		chromedp.Nodes(".tile-root", &productNodes, chromedp.ByQueryAll),
-	); err != nil {
-		return nil, err
+	if err := s.loadCards(ctx, ".tile-root", &productNodes); err != nil {
+		return nil, 0, err
 	}
-
-	for {
-		l := len(productNodes)
-		chromedp.Run(ctx,
-			// chromedp.Click("#paginatorContent", chromedp.ByID),
-			chromedp.ActionFunc(func(ctx context.Context) error {
-				_, exp, err := runtime.Evaluate(`window.scrollTo(0,document.body.scrollHeight);`).Do(ctx)
-				time.Sleep(500 * time.Millisecond)
-				if err != nil {
-					return err
-				}
-				if exp != nil {
-					return exp
-				}
-				return nil
-			}),
-			chromedp.WaitVisible(".tile-root", chromedp.ByQueryAll),
-			chromedp.Nodes(".tile-root", &productNodes, chromedp.ByQueryAll),
-		)
-		if l == len(productNodes) {
-			break
-		}
+	cards, err := s.extractCards(ctx, ".tile-root", ozonExtractScript)
+	if err != nil {
+		return nil, 0, err
 	}
+
 	products := []*model.ProductCard{}
-	for _, node := range productNodes {
-		var title string
-		var linkNodes []*cdp.Node
-		var url string
-		var fullPrice string
-		// var price string
-		var rate string
-		// var reviews string
-
-		if err := chromedp.Run(ctx,
-			chromedputils.RunWithTimeOut(
-				ctx,
-				500*time.Millisecond,
-				chromedp.Tasks{
-					chromedp.Text(".tsBody500Medium", &title, chromedp.ByQueryAll, chromedp.FromNode(node)),
-					chromedp.Text(".tsBodyMBold", &rate, chromedp.ByQueryAll, chromedp.FromNode(node)),
-					// chromedp.Text(".tsHeadline500Medium", &price, chromedp.ByQueryAll, chromedp.FromNode(node)),
-					chromedp.Text(".c3011-a0", &fullPrice, chromedp.ByQueryAll, chromedp.FromNode(node)),
-					chromedp.Nodes(".tile-hover-target", &linkNodes, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				},
-			),
-		); err != nil {
-			fmt.Println(err)
+	for _, card := range cards {
+		if card.Url == "" {
 			continue
 		}
-		url = linkNodes[0].AttributeValue("href")
-
 		product := &model.ProductCard{
-			Url:       s.prepareURL(url),
-			Title:     title,
-			FullPrice: s.prepareFullPrice(fullPrice),
-			Price:     s.preparePrice(fullPrice),
-			Rate:      s.prepareRate(rate),
-			Reviews:   s.prepareReviews(rate),
+			ID:         s.productID(card.Url),
+			Url:        s.prepareURL(card.Url),
+			Title:      card.Title,
+			Image:      card.Image,
+			FullPrice:  s.prepareFullPrice(card.Price),
+			Price:      s.preparePrice(card.Price),
+			Rate:       s.prepareRate(card.Rate),
+			Reviews:    s.prepareReviews(card.Reviews),
+			Page:       page,
+			Position:   offset + card.Index + 1,
+			Promoted:   card.Promoted,
+			Labels:     promoLabels(card.Labels),
+			Incomplete: !card.complete(),
+			Region:     s.region,
 		}
+		product.Discount = discountPercent(card.Discount, product.Price, product.FullPrice)
+		product.CardPrice = specialPrice(card.CardPrice)
+		product.Delivery = strings.Join(strings.Fields(card.Delivery), " ")
+		product.DeliveryDate, product.DeliveryDays = parseDelivery(card.Delivery, time.Now())
+		product.Fulfilment = fulfilment(card.Seller, card.Warehouse)
 		fmt.Println(product)
 		products = append(products, product)
 	}
-	return products, nil
+	return products, len(cards), nil
+}
 
+var ozonProductIDPattern = regexp.MustCompile(`/product/(?:[^/?]*-)?(\d+)(?:[/?]|$)`)
+
+func (s *ozonCatalogService) productID(url string) string {
+	if match := ozonProductIDPattern.FindStringSubmatch(url); match != nil {
+		return match[1]
+	}
+	return ""
 }
 
 func (s *ozonCatalogService) prepareURL(url string) string {
@@ -231,3 +248,26 @@ func (s *ozonCatalogService) prepareFullPrice(price string) string {
 	return res
 
 }
+
+// ozonSorting maps the sort orders to the sorting parameter of the search.
+var ozonSorting = map[string]string{
+	SortPopular:   "score",
+	SortRating:    "rating",
+	SortPriceAsc:  "price",
+	SortPriceDesc: "price_desc",
+	SortNew:       "new",
+}
+
+func (s *ozonCatalogService) SearchURL(q *model.SearchQuery) string {
+	params := url.Values{}
+	params.Set("text", q.Text)
+	params.Set("from_global", "true")
+	if v, ok := ozonSorting[q.Sort]; ok {
+		params.Set("sorting", v)
+	}
+	if q.PriceMin > 0 || q.PriceMax > 0 {
+		from, to := priceRange(q)
+		params.Set("currency_price", fmt.Sprintf("%d.000;%d.000", from, to))
+	}
+	return buildSearchURL("https://www.ozon.ru/search/", params, q)
+}
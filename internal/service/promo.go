@@ -0,0 +1,60 @@
+package service
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"wb-parser/package/money"
+)
+
+var (
+	discountPattern = regexp.MustCompile(`(\d{1,2})\s?%`)
+	// discountBadge is a badge showing nothing but the discount
+	discountBadge = regexp.MustCompile(`^[−-]?\s?\d{1,2}\s?%$`)
+)
+
+// discountPercent returns the discount shown on the card or computes it from
+// the current and the original price, empty when there is no discount.
+func discountPercent(shown string, price string, fullPrice string) string {
+	if match := discountPattern.FindStringSubmatch(shown); match != nil {
+		return match[1]
+	}
+	current, err := money.Parse(price)
+	if err != nil {
+		return ""
+	}
+	full, err := money.Parse(fullPrice)
+	if err != nil || full.Value <= current.Value {
+		return ""
+	}
+	return strconv.Itoa(int(math.Round((full.Value - current.Value) / full.Value * 100)))
+}
+
+// promoLabels returns the distinct badges of the card except the discount,
+// which has its own field.
+func promoLabels(labels []string) []string {
+	res := []string{}
+	seen := map[string]bool{}
+	for _, label := range labels {
+		label = strings.Join(strings.Fields(label), " ")
+		if label == "" || seen[label] || discountBadge.MatchString(label) {
+			continue
+		}
+		seen[label] = true
+		res = append(res, label)
+	}
+	return res
+}
+
+// specialPrice normalizes the Ozon Card or WB Wallet price.
+func specialPrice(text string) string {
+	if text == "" {
+		return ""
+	}
+	amount, err := money.Parse(text)
+	if err != nil {
+		return ""
+	}
+	return amount.String()
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// regionSetup returns the browser setup selecting the configured region with
+// set, nil when the default region of the marketplace is used.
+func (o *options) regionSetup(set func(ctx context.Context, region string) error) func(ctx context.Context) error {
+	if o.region == "" {
+		return nil
+	}
+	return func(ctx context.Context) error {
+		if err := set(ctx, o.region); err != nil {
+			return fmt.Errorf("set region %q: %w", o.region, err)
+		}
+		return nil
+	}
+}
+
+// wbDestinations maps city names to WB destination codes, any other
+// destination is passed by its numeric code.
+var wbDestinations = map[string]string{
+	"москва": "-1257786",
+	"moscow": "-1257786",
+}
+
+// setRegion selects the WB destination with the dest cookie which is read by
+// the catalog when calculating prices and delivery.
+func (s *wbCatalogService) setRegion(ctx context.Context, region string) error {
+	dest, ok := wbDestinations[strings.ToLower(region)]
+	if !ok {
+		if _, err := strconv.ParseInt(region, 10, 64); err != nil {
+			return fmt.Errorf("unknown WB region, use the numeric dest code")
+		}
+		dest = region
+	}
+	return chromedp.Run(ctx,
+		network.SetCookie("__dst", "dest="+dest).WithDomain(".wildberries.ru").WithPath("/"),
+	)
+}
+
+// setRegion enters the city into the Ozon address widget and picks the first
+// suggestion, Ozon keeps the choice in the session of the browser.
+func (s *ozonCatalogService) setRegion(ctx context.Context, region string) error {
+	if err := s.wait(ctx, MarketplaceOzon); err != nil {
+		return err
+	}
+	if err := navigate(ctx, MarketplaceOzon, "https://www.ozon.ru/"); err != nil {
+		return err
+	}
+	input := `div[role="dialog"] input`
+	return chromedp.Run(ctx,
+		chromedputils.RunWithTimeOut(ctx, waitTimeout, chromedp.Tasks{
+			chromedp.Click(`[data-widget="addressBookBarWeb"]`, chromedp.ByQuery),
+			chromedp.WaitVisible(input, chromedp.ByQuery),
+			chromedp.SendKeys(input, region, chromedp.ByQuery),
+			chromedp.WaitVisible(`div[role="dialog"] li`, chromedp.ByQuery),
+			chromedp.Click(`div[role="dialog"] li`, chromedp.ByQuery),
+			chromedp.WaitNotPresent(`div[role="dialog"]`, chromedp.ByQuery),
+		}),
+		chromedp.Sleep(time.Second),
+	)
+}
+
+var aliRegionPattern = regexp.MustCompile(`^[A-Za-z]{2}$`)
+
+// setRegion selects the Ali shipping country by its two letter code with the
+// aep_usuc_f cookie.
+func (s *aliCatalgService) setRegion(ctx context.Context, region string) error {
+	if !aliRegionPattern.MatchString(region) {
+		return fmt.Errorf("use the two letter country code")
+	}
+	value := fmt.Sprintf("site=rus&c_tp=RUB&region=%s&b_locale=ru_RU", strings.ToUpper(region))
+	return chromedp.Run(ctx,
+		network.SetCookie("aep_usuc_f", value).WithDomain(".aliexpress.ru").WithPath("/"),
+	)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	chromedputils "wb-parser/package/chromedp_utils"
+	"wb-parser/package/ratelimit"
+	"wb-parser/package/retry"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// waitTimeout limits waiting for the product cards on a loaded page.
+const waitTimeout = 30 * time.Second
+
+// retryable classifies browser errors. Block pages are handled by the block
+// policy, exceeded quota and invalid urls never succeed, everything else
+// (timeouts, network and CDP errors) is worth another attempt.
+func retryable(err error) bool {
+	var blocked *BlockedError
+	if errors.As(err, &blocked) || errors.Is(err, ratelimit.ErrQuotaExceeded) {
+		return false
+	}
+	return !strings.Contains(err.Error(), "invalid URL")
+}
+
+// stopCrawl reports whether the page error should stop the whole crawl
+// instead of skipping the page.
+func stopCrawl(ctx context.Context, err error) bool {
+	var blocked *BlockedError
+	return ctx.Err() != nil || errors.As(err, &blocked) || errors.Is(err, ratelimit.ErrQuotaExceeded)
+}
+
+func DefaultRetryPolicy() retry.Policy {
+	return retry.Policy{
+		Attempts:   3,
+		Backoff:    time.Second,
+		MaxBackoff: 30 * time.Second,
+		Jitter:     0.2,
+		Retryable:  retryable,
+	}
+}
+
+// waitNodes waits for the nodes to be visible and retrieves them.
+func (o *options) waitNodes(ctx context.Context, selector string, nodes *[]*cdp.Node) error {
+	return o.retry.Do(ctx, func(ctx context.Context) error {
+		return chromedp.Run(ctx,
+			chromedputils.RunWithTimeOut(ctx, waitTimeout, chromedp.Tasks{
+				chromedp.WaitVisible(selector, chromedp.ByQueryAll),
+				chromedp.Nodes(selector, nodes, chromedp.ByQueryAll),
+			}),
+		)
+	})
+}
@@ -0,0 +1,31 @@
+(card, index) => {
+	// class names carry a build hash that differs between catalog and store
+	// pages, so they are matched by the stable prefix
+	const text = (card, selector) => {
+		const el = card.querySelector(selector);
+		return el ? el.innerText : "";
+	};
+	const markers = ["Реклама", "Ad"];
+	const lines = (card) => card.innerText.split("\n").map((line) => line.trim());
+	const texts = (card, selector) => Array.from(card.querySelectorAll(selector)).map((el) => el.innerText.trim()).filter((t) => t);
+	const discountPattern = /^[−-]\s?\d{1,2}\s?%$/;
+	const deliveryPattern = /(сегодня|завтра|\d{1,2}\s+(января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)|\d+\s*(дн|день|дня|дней|час))/i;
+	const sellerPattern = /(склад[аеу]? продавца|доставит продавец|доставка продавца)/i;
+	const link = card.querySelector("a[class*='ProductSnippet__galleryBlock']") || card.querySelector("a[href*='/item/']");
+	const image = card.querySelector("img");
+	return {
+		index: index,
+		url: link ? link.getAttribute("href") || "" : "",
+		title: text(card, "[class*='ProductSnippet__name']"),
+		image: image ? image.getAttribute("src") || "" : "",
+		price: text(card, "[class*='SnowPrice__main']"),
+		fullPrice: text(card, "[class*='SnowPrice__second']"),
+		discount: text(card, "[class*='SnowPrice__discount']") || lines(card).find((line) => discountPattern.test(line)) || "",
+		labels: texts(card, "[class*='Badge'], [class*='Coupon'], [class*='Cashback']"),
+		delivery: text(card, "[class*='Delivery'], [class*='delivery']") || lines(card).find((line) => deliveryPattern.test(line)) || "",
+		seller: lines(card).some((line) => sellerPattern.test(line)),
+		rate: text(card, "[class*='ProductSnippet__score']"),
+		reviews: text(card, "[class*='ProductSnippet__sold']"),
+		promoted: lines(card).some((line) => markers.includes(line)),
+	};
+}
@@ -0,0 +1,16 @@
+(() => {
+	// the category menu of the home page or the subcategory list of a category page
+	const containers = document.querySelectorAll("[class*='CategoriesMenu'], [class*='categories-menu'], [class*='Subcategories'], [class*='CategoryFilter']");
+	const current = location.pathname;
+	const links = [];
+	containers.forEach((container) => {
+		container.querySelectorAll('a[href*="/category/"]').forEach((link) => {
+			const url = link.getAttribute("href") || "";
+			const name = link.innerText.trim();
+			if (name && url.split("?")[0] !== current) {
+				links.push({ name: name, url: url });
+			}
+		});
+	});
+	return links;
+})()
@@ -0,0 +1,36 @@
+(config) => {
+	const number = (text) => {
+		const match = text.replace(/[\s\u00a0\u2009\u202f]/g, "").match(/\(?(\d+)\)?$/);
+		return match ? parseInt(match[1], 10) : 0;
+	};
+	const lines = (el) => el.innerText.split("\n").map((line) => line.trim()).filter((line) => line);
+	const value = (item, index) => {
+		const link = item.closest("a") || item.querySelector("a");
+		const countEl = config.count ? item.querySelector(config.count) : null;
+		const texts = lines(item);
+		let name = texts[0] || "";
+		let count = 0;
+		if (countEl) {
+			count = number(countEl.innerText);
+			name = name.replace(countEl.innerText.trim(), "").trim();
+		} else if (texts.length > 1 && /^\(?[\d\s\u00a0\u2009\u202f]+\)?$/.test(texts[texts.length - 1])) {
+			count = number(texts[texts.length - 1]);
+		} else {
+			// "Nike (123)" in a single line
+			const match = name.match(/^(.*?)\s*\(([\d\s\u00a0\u2009\u202f]+)\)$/);
+			if (match) {
+				name = match[1];
+				count = number(match[2]);
+			}
+		}
+		return { index: index, name: name, count: count, url: link ? link.getAttribute("href") || "" : "" };
+	};
+	return Array.from(document.querySelectorAll(config.block)).map((block, index) => {
+		const title = block.querySelector(config.title);
+		return {
+			index: index,
+			name: title ? title.innerText.trim() : "",
+			values: Array.from(block.querySelectorAll(config.value)).map(value).filter((v) => v.name),
+		};
+	}).filter((facet) => facet.name && facet.values.length);
+}
@@ -0,0 +1,38 @@
+(card, index) => {
+	const text = (card, selector) => {
+		const el = card.querySelector(selector);
+		return el ? el.innerText : "";
+	};
+	const markers = ["Реклама", "Спонсорский товар", "Продвигается"];
+	const lines = (card) => card.innerText.split("\n").map((line) => line.trim());
+	const texts = (card, selector) => Array.from(card.querySelectorAll(selector)).map((el) => el.innerText.trim()).filter((t) => t);
+	const discountPattern = /^[−-]\s?\d{1,2}\s?%$/;
+	const deliveryPattern = /(сегодня|завтра|\d{1,2}\s+(января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)|\d+\s*(дн|день|дня|дней|час))/i;
+	const sellerPattern = /(склад[аеу]? продавца|доставит продавец|доставка продавца)/i;
+	const warehousePattern = /склад[аеу]?\s+(wb|wildberries|ozon|озон|маркетплейса)/i;
+	// the Ozon Card price is shown next to the "c Ozon Картой" caption
+	const cardPrice = (card) => {
+		const l = lines(card);
+		const i = l.findIndex((line) => /Ozon\s*Карт/i.test(line));
+		if (i < 0) return "";
+		return /\d/.test(l[i]) ? l[i] : l[i - 1] || "";
+	};
+	const link = card.querySelector(".tile-hover-target");
+	const image = card.querySelector("img");
+	return {
+		index: index,
+		url: link ? link.getAttribute("href") || "" : "",
+		title: text(card, ".tsBody500Medium"),
+		image: image ? image.getAttribute("src") || "" : "",
+		price: text(card, ".c3011-a0"),
+		cardPrice: cardPrice(card),
+		discount: lines(card).find((line) => discountPattern.test(line)) || "",
+		labels: texts(card, "[class*='tile-badge'], [class*='badge'] span"),
+		delivery: text(card, "[class*='delivery']") || lines(card).find((line) => deliveryPattern.test(line)) || "",
+		seller: lines(card).some((line) => sellerPattern.test(line)),
+		warehouse: lines(card).some((line) => warehousePattern.test(line)),
+		rate: text(card, ".tsBodyMBold"),
+		reviews: text(card, ".tsBodyMBold"),
+		promoted: lines(card).some((line) => markers.includes(line)),
+	};
+}
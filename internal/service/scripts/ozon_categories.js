@@ -0,0 +1,16 @@
+(() => {
+	// the catalog menu of the home page or the category filter of a category page
+	const containers = document.querySelectorAll('[data-widget="catalogMenu"], [data-widget="filtersDesktop"]');
+	const current = location.pathname;
+	const links = [];
+	containers.forEach((container) => {
+		container.querySelectorAll('a[href*="/category/"]').forEach((link) => {
+			const url = link.getAttribute("href") || "";
+			const name = link.innerText.trim();
+			if (name && url.split("?")[0] !== current) {
+				links.push({ name: name, url: url });
+			}
+		});
+	});
+	return links;
+})()
@@ -0,0 +1,30 @@
+(card, index) => {
+	const text = (card, selector) => {
+		const el = card.querySelector(selector);
+		return el ? el.innerText : "";
+	};
+	const lines = (card) => card.innerText.split("\n").map((line) => line.trim());
+	const texts = (card, selector) => Array.from(card.querySelectorAll(selector)).map((el) => el.innerText.trim()).filter((t) => t);
+	const discountPattern = /^[−-]\s?\d{1,2}\s?%$/;
+	const deliveryPattern = /(сегодня|завтра|\d{1,2}\s+(января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)|\d+\s*(дн|день|дня|дней|час))/i;
+	const sellerPattern = /(склад[аеу]? продавца|доставит продавец|доставка продавца)/i;
+	const warehousePattern = /склад[аеу]?\s+(wb|wildberries|ozon|озон|маркетплейса)/i;
+	const link = card.querySelector(".product-card__link");
+	const image = card.querySelector("img");
+	return {
+		index: index,
+		url: link ? link.getAttribute("href") || "" : "",
+		title: text(card, ".product-card__name"),
+		image: image ? image.getAttribute("src") || "" : "",
+		price: text(card, ".price__wrap"),
+		cardPrice: text(card, ".price__wallet, [class*='wallet-price']"),
+		discount: text(card, ".percentage-sale") || lines(card).find((line) => discountPattern.test(line)) || "",
+		labels: texts(card, ".product-card__tip, .product-card__badge, [class*='promo-label']"),
+		delivery: text(card, ".product-card__delivery, [class*='delivery']") || lines(card).find((line) => deliveryPattern.test(line)) || "",
+		seller: lines(card).some((line) => sellerPattern.test(line)),
+		warehouse: lines(card).some((line) => warehousePattern.test(line)),
+		rate: text(card, ".address-rate-mini"),
+		reviews: text(card, ".product-card__count"),
+		promoted: card.classList.contains("product-card--adv") || lines(card).includes("Реклама"),
+	};
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"wb-parser/internal/model"
+)
+
+// Marketplace independent sort orders. Every service maps them to its own
+// query parameter value.
+const (
+	SortPopular   = "popular"
+	SortRating    = "rating"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortNew       = "new"
+)
+
+const maxSearchPrice = 10000000
+
+// CheckSort returns an error for sort orders the marketplace search does
+// not support, an empty order keeps the marketplace default.
+func CheckSort(marketplace string, sort string) error {
+	sortings := map[string]map[string]string{
+		MarketplaceWB:   wbSorting,
+		MarketplaceOzon: ozonSorting,
+		MarketplaceAli:  aliSorting,
+	}
+	if sort == "" {
+		return nil
+	}
+	if _, ok := sortings[marketplace][sort]; !ok {
+		return fmt.Errorf("sort order %q is not supported by %s", sort, marketplace)
+	}
+	return nil
+}
+
+func buildSearchURL(base string, params url.Values, q *model.SearchQuery) string {
+	for k, v := range q.Filters {
+		params.Set(k, v)
+	}
+	return base + "?" + params.Encode()
+}
+
+// priceRange returns the price bounds of the query, substituting an open
+// upper bound with maxSearchPrice for marketplaces that require both.
+func priceRange(q *model.SearchQuery) (int, int) {
+	if q.PriceMax == 0 {
+		return q.PriceMin, maxSearchPrice
+	}
+	return q.PriceMin, q.PriceMax
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"wb-parser/internal/model"
+)
+
+const (
+	MarketplaceWB   = "wb"
+	MarketplaceOzon = "ozon"
+	MarketplaceAli  = "ali"
+)
+
+type CatalogService interface {
+	Parse(ctx context.Context, url string, pages int, output string) error
+	Collect(ctx context.Context, url string, pages int) ([]*model.ProductCard, error)
+	SearchURL(q *model.SearchQuery) string
+	// Categories returns the category tree down to depth levels, 0 is unlimited
+	Categories(ctx context.Context, depth int) ([]*model.Category, error)
+	// Facets returns the sidebar filters of the catalog with their counts
+	Facets(ctx context.Context, url string) ([]*model.Facet, error)
+}
+
+func NewCatalogService(marketplace string, opts ...Option) (CatalogService, error) {
+	switch marketplace {
+	case MarketplaceWB:
+		return NewWBCatalogService(opts...), nil
+	case MarketplaceOzon:
+		return NewOzonCatalogService(opts...), nil
+	case MarketplaceAli:
+		return NewAliCatalogService(opts...), nil
+	}
+	return nil, fmt.Errorf("unknown marketplace %q", marketplace)
+}
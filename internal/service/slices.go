@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"wb-parser/internal/model"
+	chromedputils "wb-parser/package/chromedp_utils"
+
+	"github.com/chromedp/chromedp"
+)
+
+// countFunc returns the number of products the listing url reports.
+type countFunc func(b *browser, url string) (int, error)
+
+// priceUrlFunc narrows the listing url to the price range in kopecks, both
+// bounds included.
+type priceUrlFunc func(url string, from int, to int) string
+
+// priceRangeFunc returns the price range in kopecks the listing url is
+// already narrowed to, e.g. by -price-min and -price-max of the search.
+type priceRangeFunc func(url string) (from int, to int, ok bool)
+
+// crawlPriceSlices splits the price range of the catalog, the whole one
+// unless the url already has it, into ranges listing at most sliceLimit
+// products each, crawls every range and drops products listed in several of
+// them.
+func (o *options) crawlPriceSlices(b *browser, pageUrl string, pages int, count countFunc, withPrice priceUrlFunc, priceRange priceRangeFunc, crawl crawlFunc) ([]*model.ProductCard, error) {
+	from, to, ok := priceRange(pageUrl)
+	if !ok {
+		from, to = 0, maxSearchPrice*100
+	}
+	var errs []error
+	slices, err := o.priceSlices(b, pageUrl, from, to, count, withPrice)
+	if err != nil {
+		if stopCrawl(b.parent, err) {
+			return nil, err
+		}
+		fmt.Println(err)
+		errs = append(errs, err)
+	}
+	fmt.Println("Price slices:", len(slices))
+
+	products := []*model.ProductCard{}
+	for i, u := range slices {
+		fmt.Printf("slice %d/%d: %s\n", i+1, len(slices), u)
+		parsed, err := crawl(b, u, pages)
+		products = append(products, parsed...)
+		if err != nil {
+			errs = append(errs, err)
+			if stopCrawl(b.parent, err) {
+				break
+			}
+		}
+	}
+	return dedupe(products), errors.Join(errs...)
+}
+
+// priceSlices halves the range until the listing of every part fits into
+// sliceLimit. Empty parts are skipped, a single price that still lists more
+// is kept and reported, its listing is cut by the page depth limit. When a
+// count fails, the rest of the range is returned unsliced with the error, so
+// it is still crawled up to the page depth limit.
+func (o *options) priceSlices(b *browser, pageUrl string, from int, to int, count countFunc, withPrice priceUrlFunc) ([]string, error) {
+	u := withPrice(pageUrl, from, to)
+	total, err := count(b, u)
+	if err != nil {
+		return []string{u}, fmt.Errorf("count products in %d-%d: %w", from/100, to/100, err)
+	}
+	fmt.Printf("%d-%d: %d\n", from/100, to/100, total)
+	if total == 0 {
+		return nil, nil
+	}
+	if total <= o.sliceLimit {
+		return []string{u}, nil
+	}
+	if to-from < 1 {
+		fmt.Printf("price %s lists %d products, more than -slice-limit %d: the slice will be incomplete\n", formatKopecks(from), total, o.sliceLimit)
+		return []string{u}, nil
+	}
+	mid := from + (to-from)/2
+	left, err := o.priceSlices(b, pageUrl, from, mid, count, withPrice)
+	if err != nil {
+		return append(left, withPrice(pageUrl, mid+1, to)), err
+	}
+	right, err := o.priceSlices(b, pageUrl, mid+1, to, count, withPrice)
+	return append(left, right...), err
+}
+
+// formatKopecks formats the price as rubles with kopecks.
+func formatKopecks(price int) string {
+	return fmt.Sprintf("%d.%02d", price/100, price%100)
+}
+
+// queryRange parses the "from;to" price range of the query parameter, scale
+// converts the values to kopecks.
+func queryRange(pageUrl string, key string, scale float64) (int, int, bool) {
+	u, err := url.Parse(pageUrl)
+	if err != nil {
+		return 0, 0, false
+	}
+	bounds := strings.Split(u.Query().Get(key), ";")
+	if len(bounds) != 2 {
+		return 0, 0, false
+	}
+	from, err := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	to, err := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64)
+	if err != nil || to < from {
+		return 0, 0, false
+	}
+	return int(math.Round(from * scale)), int(math.Round(to * scale)), true
+}
+
+// setQuery replaces the query parameter of the url.
+func setQuery(pageUrl string, key string, value string) string {
+	u, err := url.Parse(pageUrl)
+	if err != nil {
+		return pageUrl
+	}
+	q := u.Query()
+	q.Set(key, value)
+	// pagination is added by the page loop
+	q.Del("page")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (s *wbCatalogService) withPrice(pageUrl string, from int, to int) string {
+	return setQuery(pageUrl, "priceU", fmt.Sprintf("%d;%d", from, to))
+}
+
+// priceRange reads priceU, WB keeps it in kopecks.
+func (s *wbCatalogService) priceRange(pageUrl string) (int, int, bool) {
+	return queryRange(pageUrl, "priceU", 1)
+}
+
+func (s *wbCatalogService) countProducts(b *browser, pageUrl string) (int, error) {
+	if err := s.open(b, pageUrl); err != nil {
+		return 0, err
+	}
+	total, err := s.parseTotalProducts(b.ctx)
+	if err != nil {
+		// empty listings show no counter
+		var cards bool
+		if chromedp.Run(b.ctx, chromedp.Evaluate(`document.querySelector(".product-card") !== null`, &cards)) == nil && !cards {
+			return 0, nil
+		}
+	}
+	return total, err
+}
+
+func (s *ozonCatalogService) withPrice(pageUrl string, from int, to int) string {
+	// Ozon expects rubles with three decimals
+	price := func(kopecks int) string {
+		return fmt.Sprintf("%d.%03d", kopecks/100, kopecks%100*10)
+	}
+	return setQuery(pageUrl, "currency_price", price(from)+";"+price(to))
+}
+
+// priceRange reads currency_price, Ozon keeps it in rubles.
+func (s *ozonCatalogService) priceRange(pageUrl string) (int, int, bool) {
+	return queryRange(pageUrl, "currency_price", 100)
+}
+
+var ozonTotalPattern = regexp.MustCompile(`(\d[\d\s\x{00a0}\x{2009}\x{202f}]*)\s*товар`)
+
+func (s *ozonCatalogService) countProducts(b *browser, pageUrl string) (int, error) {
+	if err := s.open(b, pageUrl); err != nil {
+		return 0, err
+	}
+	var header string
+	if err := chromedp.Run(b.ctx,
+		chromedputils.RunWithTimeOut(b.ctx, waitTimeout, chromedp.Tasks{
+			chromedp.WaitReady("body", chromedp.ByQuery),
+			chromedp.Sleep(time.Second),
+			chromedp.Evaluate(`(() => {
+				const header = document.querySelector('[data-widget="resultsHeader"], [data-widget="searchResultsHeader"], [data-widget="fulltextResultsHeader"]');
+				return header ? header.innerText : document.body.innerText.slice(0, 3000);
+			})()`, &header),
+		}),
+	); err != nil {
+		return 0, err
+	}
+	if strings.Contains(header, "ничего не нашлось") || strings.Contains(header, "ничего не найдено") {
+		return 0, nil
+	}
+	match := ozonTotalPattern.FindStringSubmatch(header)
+	if match == nil {
+		return 0, errors.New("no products count on the page")
+	}
+	return strconv.Atoi(strings.Join(strings.Fields(match[1]), ""))
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPriceSlicesCountError(t *testing.T) {
+	o := &options{sliceLimit: 10}
+	withPrice := func(pageUrl string, from int, to int) string {
+		return fmt.Sprintf("%d-%d", from, to)
+	}
+	count := func(b *browser, u string) (int, error) {
+		switch u {
+		case "0-400":
+			return 30, nil
+		case "0-200":
+			return 20, nil
+		case "0-100":
+			return 5, nil
+		}
+		return 0, errors.New("no counter")
+	}
+	slices, err := o.priceSlices(nil, "", 0, 400, count, withPrice)
+	if err == nil {
+		t.Error("count error is lost")
+	}
+	// the uncounted 101-200 and the remaining 201-400 are crawled unsliced
+	want := []string{"0-100", "101-200", "201-400"}
+	if !reflect.DeepEqual(slices, want) {
+		t.Errorf("slices = %v, want %v", slices, want)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"wb-parser/internal/model"
+)
+
+// Listing kinds recognised by the url.
+const (
+	ListingCatalog = "catalog" // category or search results
+	ListingBrand   = "brand"
+	ListingSeller  = "seller"
+)
+
+// listing is the kind of the crawled url with the brand or seller it belongs to.
+type listing struct {
+	kind string
+	id   string
+}
+
+// tag marks products of a brand page or a seller storefront with its id.
+func (l listing) tag(products []*model.ProductCard) {
+	for _, product := range products {
+		switch l.kind {
+		case ListingBrand:
+			product.Brand = l.id
+		case ListingSeller:
+			product.Seller = l.id
+		}
+	}
+}
+
+var (
+	wbBrandPattern  = regexp.MustCompile(`/brands/([^/?#]+)`)
+	wbSellerPattern = regexp.MustCompile(`/seller/(\d+)`)
+)
+
+// listing recognises WB brand pages /brands/<name> and seller storefronts
+// /seller/<id>, both use the catalog cards and pagination.
+func (s *wbCatalogService) listing(url string) listing {
+	if match := wbBrandPattern.FindStringSubmatch(url); match != nil {
+		return listing{kind: ListingBrand, id: match[1]}
+	}
+	if match := wbSellerPattern.FindStringSubmatch(url); match != nil {
+		return listing{kind: ListingSeller, id: match[1]}
+	}
+	return listing{kind: ListingCatalog}
+}
+
+var (
+	ozonBrandPattern  = regexp.MustCompile(`/brand/(?:[^/?]*-)?(\d+)(?:[/?]|$)`)
+	ozonSellerPattern = regexp.MustCompile(`/seller/(?:[^/?]*-)?(\d+)(?:[/?]|$)`)
+)
+
+// listing recognises Ozon brand pages /brand/<name>-<id> and seller
+// storefronts /seller/<name>-<id>, both use the catalog tiles and pagination.
+func (s *ozonCatalogService) listing(url string) listing {
+	if match := ozonBrandPattern.FindStringSubmatch(url); match != nil {
+		return listing{kind: ListingBrand, id: match[1]}
+	}
+	if match := ozonSellerPattern.FindStringSubmatch(url); match != nil {
+		return listing{kind: ListingSeller, id: match[1]}
+	}
+	return listing{kind: ListingCatalog}
+}
+
+var aliStorePattern = regexp.MustCompile(`/store/(\d+)`)
+
+const (
+	aliCardSelector = ".product-snippet_ProductSnippet__content__1mogfw"
+	// store pages render the snippets with another build hash in the class
+	// names, ali.js matches the card content by the prefix for both pages
+	aliStoreCardSelector = "[class*='product-snippet_ProductSnippet__content']"
+)
+
+// listing recognises Ali store pages /store/<id>.
+func (s *aliCatalgService) listing(url string) listing {
+	if match := aliStorePattern.FindStringSubmatch(url); match != nil {
+		return listing{kind: ListingSeller, id: match[1]}
+	}
+	return listing{kind: ListingCatalog}
+}
+
+// storeItemsUrl returns the paginated list of all items of the store, the
+// store home page shows only a selection of them.
+func (s *aliCatalgService) storeItemsUrl(id string) string {
+	return fmt.Sprintf("https://aliexpress.ru/store/%s/pages/all-items.html", id)
+}
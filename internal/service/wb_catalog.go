@@ -5,7 +5,9 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +15,6 @@ import (
 	chromedputils "wb-parser/package/chromedp_utils"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 )
 
@@ -21,26 +22,40 @@ const (
 	itemPerPage = 100
 )
 
-type wbCatalogService struct{}
+type wbCatalogService struct {
+	options
+}
+
+func NewWBCatalogService(opts ...Option) *wbCatalogService {
+	return &wbCatalogService{options: newOptions(opts)}
+}
 
-func NewWBCatalogService() *wbCatalogService {
-	return &wbCatalogService{}
+func (s *wbCatalogService) Parse(ctx context.Context, wbCatalogUrl string, pages int, output string) error {
+	// products collected before an error are still written
+	products, err := s.Collect(ctx, wbCatalogUrl, pages)
+	return errors.Join(
+		err,
+		s.writeResults(ctx, products, output),
+		s.save(ctx, MarketplaceWB, wbCatalogUrl, products, err),
+	)
 }
 
-func (s *wbCatalogService) Parse(ctx context.Context, wbCatalogUrl string, pages int, output string) {
-	cctx, cancel := chromedputils.InitChromeDPContext(ctx)
-	defer cancel()
-	products, err := s.parsewbCatalog(cctx, wbCatalogUrl, pages)
+func (s *wbCatalogService) Collect(ctx context.Context, wbCatalogUrl string, pages int) ([]*model.ProductCard, error) {
+	b, err := s.startBrowser(ctx, MarketplaceWB, s.regionSetup(s.setRegion))
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	if err := s.writeResults(ctx, products, output); err != nil {
-		fmt.Println(err)
+	defer b.close()
+	switch {
+	case s.facetSplit != "":
+		return s.crawlFacet(b, wbCatalogUrl, pages, wbFacets, s.countProducts, s.parsewbCatalog)
+	case s.sliceLimit > 0:
+		return s.crawlPriceSlices(b, wbCatalogUrl, pages, s.countProducts, s.withPrice, s.priceRange, s.parsewbCatalog)
 	}
-
+	return s.parsewbCatalog(b, wbCatalogUrl, pages)
 }
 
-func (s *wbCatalogService) parsewbCatalog(ctx context.Context, wbCatalogUrl string, pages int) ([]*model.ProductCard, error) {
+func (s *wbCatalogService) parsewbCatalog(b *browser, wbCatalogUrl string, pages int) ([]*model.ProductCard, error) {
 	// // Navigate
 	// if err := chromedp.Run(ctx,
 	// 	chromedp.Navigate(wbCatalogUrl),
@@ -58,6 +73,8 @@ func (s *wbCatalogService) parsewbCatalog(ctx context.Context, wbCatalogUrl stri
 	totalPages := pages
 
 	products := []*model.ProductCard{}
+	position := 0
+	listing := s.listing(wbCatalogUrl)
 
 	for i := 1; i < totalPages; i++ {
 
@@ -71,92 +88,82 @@ func (s *wbCatalogService) parsewbCatalog(ctx context.Context, wbCatalogUrl stri
 		}
 		fmt.Println(pageUrl)
 		// Navigate
-		if err := chromedp.Run(ctx,
-			chromedp.Navigate(pageUrl),
-		); err != nil {
-			return nil, err
+		if err := s.open(b, pageUrl); err != nil {
+			if stopCrawl(b.parent, err) {
+				return products, err
+			}
+			fmt.Println(err)
+			continue
 		}
-		parsedProducts, err := s.parseProducts(ctx)
+		parsedProducts, cards, err := s.parseProducts(b.ctx, i, position)
+		listing.tag(parsedProducts)
+		observePage(MarketplaceWB, parsedProducts, cards)
+		s.progress(i, parsedProducts)
 		if err != nil {
 			continue
 		}
+		position += cards
 		products = append(products, parsedProducts...)
 	}
 	return products, nil
 }
 
-func (s *wbCatalogService) parseProducts(ctx context.Context) ([]*model.ProductCard, error) {
+func (s *wbCatalogService) parseProducts(ctx context.Context, page int, offset int) ([]*model.ProductCard, int, error) {
 	var productNodes []*cdp.Node
 
-	if err := chromedp.Run(
-		ctx,
-		chromedp.WaitVisible(".product-card", chromedp.ByQueryAll),
-		chromedp.Nodes(".product-card", &productNodes, chromedp.ByQueryAll),
-	); err != nil {
-		return nil, err
-	}
-
-	for {
-		l := len(productNodes)
-		chromedp.Run(ctx,
+	// close popups covering the listing
+	chromedp.Run(ctx,
+		chromedputils.RunWithTimeOut(ctx, time.Second, chromedp.Tasks{
 			chromedp.Click("#body-layout", chromedp.ByID),
-			chromedp.ActionFunc(func(ctx context.Context) error {
-				_, exp, err := runtime.Evaluate(`window.scrollTo(0,document.body.scrollHeight);`).Do(ctx)
-				time.Sleep(500 * time.Millisecond)
-				if err != nil {
-					return err
-				}
-				if exp != nil {
-					return exp
-				}
-				return nil
-			}),
-			chromedp.WaitVisible(".product-card", chromedp.ByQueryAll),
-			chromedp.Nodes(".product-card", &productNodes, chromedp.ByQueryAll),
-		)
-		if l == len(productNodes) {
-			break
-		}
+		}),
+	)
+	if err := s.loadCards(ctx, ".product-card", &productNodes); err != nil {
+		return nil, 0, err
 	}
-	fmt.Println(len(productNodes))
-	products := []*model.ProductCard{}
-
-	for _, node := range productNodes {
-		var productTitle string
-		// var url string
-		var linkNodes []*cdp.Node
-		var url string
-		var fullPrice string
-		var rate string
-		var reviews string
-
-		if err := chromedp.Run(ctx,
-			chromedputils.RunWithTimeOut(ctx, 500*time.Millisecond, chromedp.Tasks{
-
-				chromedp.Text(".product-card__name", &productTitle, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				chromedp.Text(".price__wrap", &fullPrice, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				chromedp.Nodes(".product-card__link", &linkNodes, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				chromedp.Text(".address-rate-mini", &rate, chromedp.ByQueryAll, chromedp.FromNode(node)),
-				chromedp.Text(".product-card__count", &reviews, chromedp.ByQueryAll, chromedp.FromNode(node)),
-			}),
-		); err != nil {
+	cards, err := s.extractCards(ctx, ".product-card", wbExtractScript)
+	if err != nil {
+		return nil, 0, err
+	}
+	fmt.Println(len(cards))
 
+	products := []*model.ProductCard{}
+	for _, card := range cards {
+		if card.Url == "" {
 			continue
 		}
-		url = linkNodes[0].AttributeValue("href")
+		product := &model.ProductCard{
+			ID:         s.productID(card.Url),
+			Url:        card.Url,
+			Title:      s.prepareTitle(card.Title),
+			Image:      card.Image,
+			FullPrice:  s.prepareFullPrice(card.Price),
+			Price:      s.preparePrice(card.Price),
+			Rate:       card.Rate,
+			Reviews:    s.prepareReviews(card.Reviews),
+			Page:       page,
+			Position:   offset + card.Index + 1,
+			Promoted:   card.Promoted,
+			Labels:     promoLabels(card.Labels),
+			Incomplete: !card.complete(),
+			Region:     s.region,
+		}
+		product.Discount = discountPercent(card.Discount, product.Price, product.FullPrice)
+		product.CardPrice = specialPrice(card.CardPrice)
+		product.Delivery = strings.Join(strings.Fields(card.Delivery), " ")
+		product.DeliveryDate, product.DeliveryDays = parseDelivery(card.Delivery, time.Now())
+		product.Fulfilment = fulfilment(card.Seller, card.Warehouse)
+		products = append(products, product)
+	}
+	return products, len(cards), nil
+}
 
-		// fmt.Println(productTitle, url, fullPrice, rate, reviews)
+var wbProductIDPattern = regexp.MustCompile(`/catalog/(\d+)/`)
 
-		products = append(products, &model.ProductCard{
-			Url:       url,
-			Title:     s.prepareTitle(productTitle),
-			FullPrice: s.prepareFullPrice(fullPrice),
-			Price:     s.preparePrice(fullPrice),
-			Rate:      rate,
-			Reviews:   s.prepareReviews(reviews),
-		})
+func (s *wbCatalogService) productID(url string) string {
+	if match := wbProductIDPattern.FindStringSubmatch(url); match != nil {
+		return match[1]
 	}
-	return products, nil
+	return ""
 }
 
 func (s *wbCatalogService) prepareTitle(title string) string {
@@ -209,22 +216,28 @@ func (s *wbCatalogService) parseTotalProducts(ctx context.Context) (int, error)
 		}),
 	); err != nil {
 		chromedp.Run(ctx,
-			chromedp.WaitVisible(".searching-results__count", chromedp.ByQueryAll),
-			chromedp.Nodes(".searching-results__count", &totalGoods, chromedp.ByQueryAll),
+			chromedputils.RunWithTimeOut(ctx, waitTimeout, chromedp.Tasks{
+				chromedp.WaitVisible(".searching-results__count", chromedp.ByQueryAll),
+				chromedp.Nodes(".searching-results__count", &totalGoods, chromedp.ByQueryAll),
+			}),
 		)
 	}
 	if len(totalGoods) == 0 {
 		return 0, errors.New("no goods")
 	}
 	var total string
-	chromedp.Run(ctx,
-		chromedp.Text(totalGoods[0].FullXPath()+"/span", &total, chromedp.BySearch),
-	)
+	if err := chromedp.Run(ctx,
+		chromedputils.RunWithTimeOut(ctx, waitTimeout, chromedp.Tasks{
+			chromedp.Text(totalGoods[0].FullXPath()+"/span", &total, chromedp.BySearch),
+		}),
+	); err != nil {
+		return 0, err
+	}
 	return s.convertTotalProducts(total)
 }
 
 func (s *wbCatalogService) convertTotalProducts(total string) (int, error) {
-	sTotal := strings.Split(total, " ")
+	sTotal := strings.Fields(total)
 	jTotal := strings.Join(sTotal, "")
 	return strconv.Atoi(jTotal)
 }
@@ -243,19 +256,38 @@ func (s *wbCatalogService) writeResults(ctx context.Context, products []*model.P
 	csvw := csv.NewWriter(f)
 
 	if err := csvw.Write([]string{
-		"title", "url", "price", "full_price", "rate", "reviews",
+		"id", "title", "url", "image", "price", "full_price", "rate", "reviews", "page", "position", "promoted", "incomplete", "currency", "region",
+		"discount", "card_price", "labels", "delivery", "delivery_date", "delivery_days", "fulfilment",
+		"brand", "seller",
 	}); err != nil {
 		return err
 	}
 
 	for _, product := range products {
 		row := []string{
+			product.ID,
 			product.Title,
 			product.Url,
+			product.Image,
 			product.Price,
 			product.FullPrice,
 			product.Rate,
 			product.Reviews,
+			strconv.Itoa(product.Page),
+			strconv.Itoa(product.Position),
+			strconv.FormatBool(product.Promoted),
+			strconv.FormatBool(product.Incomplete),
+			product.Currency,
+			product.Region,
+			product.Discount,
+			product.CardPrice,
+			strings.Join(product.Labels, "; "),
+			product.Delivery,
+			product.DeliveryDate,
+			product.DeliveryDays,
+			product.Fulfilment,
+			product.Brand,
+			product.Seller,
 		}
 		if err := csvw.Write(row); err != nil {
 			return err
@@ -267,3 +299,26 @@ func (s *wbCatalogService) writeResults(ctx context.Context, products []*model.P
 	}
 	return nil
 }
+
+// wbSorting maps the sort orders to the sort parameter of the search.
+var wbSorting = map[string]string{
+	SortPopular:   "popular",
+	SortRating:    "rate",
+	SortPriceAsc:  "priceup",
+	SortPriceDesc: "pricedown",
+	SortNew:       "newly",
+}
+
+func (s *wbCatalogService) SearchURL(q *model.SearchQuery) string {
+	params := url.Values{}
+	params.Set("search", q.Text)
+	if v, ok := wbSorting[q.Sort]; ok {
+		params.Set("sort", v)
+	}
+	if q.PriceMin > 0 || q.PriceMax > 0 {
+		from, to := priceRange(q)
+		// WB expects prices in kopecks
+		params.Set("priceU", fmt.Sprintf("%d;%d", from*100, to*100))
+	}
+	return buildSearchURL("https://www.wildberries.ru/catalog/0/search.aspx", params, q)
+}
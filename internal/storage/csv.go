@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"strings"
+	"wb-parser/internal/model"
+)
+
+// ReadCSV loads products from the result file written by the parsers.
+// Columns are matched by header so files of older versions are supported too.
+func ReadCSV(path string) ([]*model.ProductCard, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[name] = i
+	}
+	value := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	products := []*model.ProductCard{}
+	for _, row := range rows[1:] {
+		page, _ := strconv.Atoi(value(row, "page"))
+		position, _ := strconv.Atoi(value(row, "position"))
+		promoted, _ := strconv.ParseBool(value(row, "promoted"))
+		incomplete, _ := strconv.ParseBool(value(row, "incomplete"))
+		products = append(products, &model.ProductCard{
+			ID:           value(row, "id"),
+			Url:          value(row, "url"),
+			Title:        value(row, "title"),
+			Image:        value(row, "image"),
+			Price:        value(row, "price"),
+			FullPrice:    value(row, "full_price"),
+			Rate:         value(row, "rate"),
+			Reviews:      value(row, "reviews"),
+			Page:         page,
+			Position:     position,
+			Promoted:     promoted,
+			Incomplete:   incomplete,
+			Currency:     value(row, "currency"),
+			Region:       value(row, "region"),
+			Discount:     value(row, "discount"),
+			CardPrice:    value(row, "card_price"),
+			Labels:       labels(value(row, "labels")),
+			Delivery:     value(row, "delivery"),
+			DeliveryDate: value(row, "delivery_date"),
+			DeliveryDays: value(row, "delivery_days"),
+			Fulfilment:   value(row, "fulfilment"),
+			Brand:        value(row, "brand"),
+			Seller:       value(row, "seller"),
+		})
+	}
+	return products, nil
+}
+
+func labels(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, "; ")
+}
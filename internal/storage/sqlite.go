@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wb-parser/internal/model"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	marketplace TEXT NOT NULL,
+	url         TEXT NOT NULL,
+	region      TEXT NOT NULL DEFAULT '',
+	started_at  TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS products (
+	marketplace TEXT NOT NULL,
+	product_id  TEXT NOT NULL,
+	url         TEXT NOT NULL,
+	title       TEXT NOT NULL,
+	image       TEXT NOT NULL,
+	first_seen  TIMESTAMP NOT NULL,
+	last_seen   TIMESTAMP NOT NULL,
+	PRIMARY KEY (marketplace, product_id)
+);
+CREATE TABLE IF NOT EXISTS observations (
+	run_id      INTEGER NOT NULL REFERENCES runs (id),
+	marketplace TEXT NOT NULL,
+	product_id  TEXT NOT NULL,
+	price       REAL,
+	full_price  REAL,
+	rate        REAL,
+	reviews     INTEGER,
+	page        INTEGER NOT NULL,
+	position    INTEGER NOT NULL,
+	promoted    BOOLEAN NOT NULL,
+	observed_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS observations_product ON observations (marketplace, product_id, observed_at);
+`
+
+var ErrRunNotFound = errors.New("run not found")
+
+// Observation is a product state captured by a single run.
+type Observation struct {
+	RunID      int64
+	Region     string
+	ObservedAt time.Time
+	Price      sql.NullFloat64
+	FullPrice  sql.NullFloat64
+	Rate       sql.NullFloat64
+	Reviews    sql.NullInt64
+	Position   int
+}
+
+type Run struct {
+	ID          int64
+	Marketplace string
+	Url         string
+	Region      string
+	StartedAt   time.Time
+	Products    int
+}
+
+// SQLiteStorage keeps every product once and appends its price, rating and
+// reviews on every run.
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite allows a single writer, serialize access of concurrent crawls
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStorage) Save(ctx context.Context, marketplace string, url string, region string, products []*model.ProductCard) error {
+	_, err := s.SaveRun(ctx, marketplace, url, region, products)
+	return err
+}
+
+// SaveRun stores products of the run and returns the run id. Region is the
+// delivery region of the crawl, empty for the default one.
+func (s *SQLiteStorage) SaveRun(ctx context.Context, marketplace string, url string, region string, products []*model.ProductCard) (int64, error) {
+	now := time.Now().UTC()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO runs (marketplace, url, region, started_at) VALUES (?, ?, ?, ?)`,
+		marketplace, url, region, now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, product := range products {
+		id := product.Key()
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO products (marketplace, product_id, url, title, image, first_seen, last_seen)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (marketplace, product_id) DO UPDATE SET
+				url = excluded.url,
+				title = excluded.title,
+				image = excluded.image,
+				last_seen = excluded.last_seen`,
+			marketplace, id, product.Url, product.Title, product.Image, now, now,
+		); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO observations (run_id, marketplace, product_id, price, full_price, rate, reviews, page, position, promoted, observed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			runID, marketplace, id,
+			parseFloat(product.Price),
+			parseFloat(product.FullPrice),
+			parseFloat(product.Rate),
+			parseInt(product.Reviews),
+			product.Page, product.Position, product.Promoted, now,
+		); err != nil {
+			return 0, err
+		}
+	}
+	return runID, tx.Commit()
+}
+
+// PriceHistory returns all observations of the product ordered by time.
+func (s *SQLiteStorage) PriceHistory(ctx context.Context, marketplace string, productID string) ([]*Observation, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT o.run_id, r.region, o.observed_at, o.price, o.full_price, o.rate, o.reviews, o.position
+		FROM observations o
+		JOIN runs r ON r.id = o.run_id
+		WHERE o.marketplace = ? AND o.product_id = ?
+		ORDER BY o.observed_at`,
+		marketplace, productID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	observations := []*Observation{}
+	for rows.Next() {
+		o := &Observation{}
+		if err := rows.Scan(&o.RunID, &o.Region, &o.ObservedAt, &o.Price, &o.FullPrice, &o.Rate, &o.Reviews, &o.Position); err != nil {
+			return nil, err
+		}
+		observations = append(observations, o)
+	}
+	return observations, rows.Err()
+}
+
+func (s *SQLiteStorage) Runs(ctx context.Context) ([]*Run, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.marketplace, r.url, r.region, r.started_at, COUNT(o.run_id)
+		FROM runs r
+		LEFT JOIN observations o ON o.run_id = r.id
+		GROUP BY r.id
+		ORDER BY r.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*Run{}
+	for rows.Next() {
+		r := &Run{}
+		if err := rows.Scan(&r.ID, &r.Marketplace, &r.Url, &r.Region, &r.StartedAt, &r.Products); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// LastRun returns id of the latest run of the url in the region or 0 if there
+// are no runs.
+func (s *SQLiteStorage) LastRun(ctx context.Context, marketplace string, url string, region string) (int64, error) {
+	var id sql.NullInt64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT MAX(id) FROM runs WHERE marketplace = ? AND url = ? AND region = ?`,
+		marketplace, url, region,
+	).Scan(&id)
+	return id.Int64, err
+}
+
+// RunProducts returns products as they were observed by the run,
+// ErrRunNotFound when there is no such run.
+func (s *SQLiteStorage) RunProducts(ctx context.Context, runID int64) ([]*model.ProductCard, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM runs WHERE id = ?)`, runID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("run %d: %w", runID, ErrRunNotFound)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.product_id, p.url, p.title, p.image, o.price, o.full_price, o.rate, o.reviews, o.page, o.position, o.promoted, r.region
+		FROM observations o
+		JOIN products p ON p.marketplace = o.marketplace AND p.product_id = o.product_id
+		JOIN runs r ON r.id = o.run_id
+		WHERE o.run_id = ?
+		ORDER BY o.position`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []*model.ProductCard{}
+	for rows.Next() {
+		var price, fullPrice, rate sql.NullFloat64
+		var reviews sql.NullInt64
+		product := &model.ProductCard{}
+		if err := rows.Scan(
+			&product.ID, &product.Url, &product.Title, &product.Image,
+			&price, &fullPrice, &rate, &reviews,
+			&product.Page, &product.Position, &product.Promoted, &product.Region,
+		); err != nil {
+			return nil, err
+		}
+		product.Price = formatFloat(price)
+		product.FullPrice = formatFloat(fullPrice)
+		product.Rate = formatFloat(rate)
+		if reviews.Valid {
+			product.Reviews = strconv.FormatInt(reviews.Int64, 10)
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
+func parseFloat(value string) sql.NullFloat64 {
+	f, ok := model.Number(value)
+	return sql.NullFloat64{Float64: f, Valid: ok}
+}
+
+func parseInt(value string) sql.NullInt64 {
+	i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: i, Valid: true}
+}
+
+func formatFloat(value sql.NullFloat64) string {
+	if !value.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(value.Float64, 'f', -1, 64)
+}
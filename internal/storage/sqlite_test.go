@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"wb-parser/internal/model"
+)
+
+func TestRunProducts(t *testing.T) {
+	st, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "products.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	run, err := st.SaveRun(ctx, "wb", "https://example.com/catalog", "", []*model.ProductCard{
+		{ID: "1", Url: "https://example.com/1", Title: "Коробка", Price: "100", Position: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	products, err := st.RunProducts(ctx, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 || products[0].ID != "1" || products[0].Price != "100" {
+		t.Errorf("products = %+v", products)
+	}
+
+	if _, err := st.RunProducts(ctx, run+1); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("unknown run error = %v, want ErrRunNotFound", err)
+	}
+}
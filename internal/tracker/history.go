@@ -0,0 +1,71 @@
+package tracker
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Record is a single rank observation of the tracked product.
+type Record struct {
+	Time        time.Time `json:"time"`
+	Marketplace string    `json:"marketplace"`
+	Keyword     string    `json:"keyword"`
+	ProductID   string    `json:"product_id"`
+	Page        int       `json:"page"`
+	Position    int       `json:"position"` // 0 if the product was not found
+	Promoted    bool      `json:"promoted"`
+}
+
+// History is an append-only JSON lines file with rank observations.
+type History struct {
+	path string
+}
+
+func NewHistory(path string) *History {
+	return &History{path: path}
+}
+
+func (h *History) Append(records []*Record) error {
+	if err := os.MkdirAll(filepath.Dir(h.path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *History) Load() ([]*Record, error) {
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []*Record{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
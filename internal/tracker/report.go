@@ -0,0 +1,79 @@
+package tracker
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// ReportRow describes how the product rank changed between two latest runs.
+type ReportRow struct {
+	Marketplace string
+	Keyword     string
+	ProductID   string
+	Current     *Record
+	Previous    *Record
+}
+
+// Change returns rank improvement since the previous run, positive when the
+// product moved up.
+func (r *ReportRow) Change() (int, bool) {
+	if r.Previous == nil || r.Previous.Position == 0 || r.Current.Position == 0 {
+		return 0, false
+	}
+	return r.Previous.Position - r.Current.Position, true
+}
+
+func BuildReport(records []*Record) []*ReportRow {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+
+	rows := []*ReportRow{}
+	index := map[[3]string]*ReportRow{}
+	for _, record := range records {
+		key := [3]string{record.Marketplace, record.Keyword, record.ProductID}
+		row, ok := index[key]
+		if !ok {
+			row = &ReportRow{Marketplace: record.Marketplace, Keyword: record.Keyword, ProductID: record.ProductID}
+			index[key] = row
+			rows = append(rows, row)
+		}
+		row.Previous, row.Current = row.Current, record
+	}
+	return rows
+}
+
+func WriteReport(w io.Writer, rows []*ReportRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "marketplace\tkeyword\tproduct\tposition\tprevious\tchange\tchecked")
+	for _, row := range rows {
+		previous := "-"
+		if row.Previous != nil {
+			previous = formatPosition(row.Previous.Position)
+		}
+		change := "-"
+		if delta, ok := row.Change(); ok {
+			change = fmt.Sprintf("%+d", delta)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Marketplace,
+			row.Keyword,
+			row.ProductID,
+			formatPosition(row.Current.Position),
+			previous,
+			change,
+			row.Current.Time.Format("2006-01-02 15:04"),
+		)
+	}
+	return tw.Flush()
+}
+
+func formatPosition(position int) string {
+	if position == 0 {
+		return "not found"
+	}
+	return strconv.Itoa(position)
+}
@@ -0,0 +1,50 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	record := func(days int, keyword string, position int) *Record {
+		return &Record{Time: day.AddDate(0, 0, days), Marketplace: "wb", Keyword: keyword, ProductID: "1", Position: position}
+	}
+	// records of a keyword are out of order to check the sort by time
+	rows := BuildReport([]*Record{
+		record(2, "швабра", 3),
+		record(0, "швабра", 10),
+		record(1, "швабра", 8),
+		record(0, "ведро", 5),
+		record(1, "ведро", 0),
+		record(0, "таз", 4),
+	})
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	tests := []struct {
+		row      *ReportRow
+		keyword  string
+		current  int
+		previous int
+		change   int
+		ok       bool
+	}{
+		{rows[0], "швабра", 3, 8, 5, true},
+		{rows[1], "ведро", 0, 5, 0, false}, // not found in the latest run
+		{rows[2], "таз", 4, 0, 0, false},   // a single run
+	}
+	for _, tt := range tests {
+		if tt.row.Keyword != tt.keyword || tt.row.Current.Position != tt.current {
+			t.Errorf("row = %s %d, want %s %d", tt.row.Keyword, tt.row.Current.Position, tt.keyword, tt.current)
+			continue
+		}
+		if tt.row.Previous != nil && tt.row.Previous.Position != tt.previous {
+			t.Errorf("%s: previous = %d, want %d", tt.keyword, tt.row.Previous.Position, tt.previous)
+		}
+		if change, ok := tt.row.Change(); change != tt.change || ok != tt.ok {
+			t.Errorf("%s: Change() = %d %v, want %d %v", tt.keyword, change, ok, tt.change, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"wb-parser/internal/model"
+	"wb-parser/internal/service"
+)
+
+// Target is a product which rank is tracked for the keyword.
+type Target struct {
+	Marketplace string `json:"marketplace"`
+	Keyword     string `json:"keyword"`
+	ProductID   string `json:"product_id"`
+}
+
+func LoadTargets(path string) ([]*Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	targets := []*Target{}
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+type Tracker struct {
+	targets []*Target
+	history *History
+	pages   int
+}
+
+func NewTracker(targets []*Target, history *History, pages int) *Tracker {
+	return &Tracker{targets: targets, history: history, pages: pages}
+}
+
+// Run crawls every tracked keyword once and appends the found ranks to the
+// history. Targets of a keyword whose crawl failed get no record, so a failed
+// run does not look like the products dropped out of the search.
+func (t *Tracker) Run(ctx context.Context) error {
+	now := time.Now()
+	records := []*Record{}
+	var errs []error
+
+	for _, search := range t.searches() {
+		s, err := service.NewCatalogService(search.marketplace)
+		if err != nil {
+			return err
+		}
+		url := s.SearchURL(&model.SearchQuery{Text: search.keyword})
+		products, err := s.Collect(ctx, url, t.pages)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", search.marketplace, search.keyword, err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		for _, target := range search.targets {
+			record := &Record{
+				Time:        now,
+				Marketplace: target.Marketplace,
+				Keyword:     target.Keyword,
+				ProductID:   target.ProductID,
+			}
+			for _, product := range products {
+				if product.ID == target.ProductID {
+					record.Page = product.Page
+					record.Position = product.Position
+					record.Promoted = product.Promoted
+					break
+				}
+			}
+			records = append(records, record)
+		}
+	}
+	return errors.Join(append(errs, t.history.Append(records))...)
+}
+
+type search struct {
+	marketplace string
+	keyword     string
+	targets     []*Target
+}
+
+// searches groups targets so that every keyword is crawled once per marketplace.
+func (t *Tracker) searches() []*search {
+	searches := []*search{}
+	index := map[[2]string]*search{}
+	for _, target := range t.targets {
+		key := [2]string{target.Marketplace, target.Keyword}
+		s, ok := index[key]
+		if !ok {
+			s = &search{marketplace: target.Marketplace, keyword: target.Keyword}
+			index[key] = s
+			searches = append(searches, s)
+		}
+		s.targets = append(s.targets, target)
+	}
+	return searches
+}
@@ -6,8 +6,9 @@ import (
 	"github.com/chromedp/chromedp"
 )
 
-func InitChromeDPContext(ctx context.Context) (context.Context, context.CancelFunc) {
-	initialCtx, _ := chromedp.NewExecAllocator(ctx, chromedp.Flag("headless", false))
+func InitChromeDPContext(ctx context.Context, opts ...chromedp.ExecAllocatorOption) (context.Context, context.CancelFunc) {
+	opts = append([]chromedp.ExecAllocatorOption{chromedp.Flag("headless", false)}, opts...)
+	initialCtx, _ := chromedp.NewExecAllocator(ctx, opts...)
 	cctx, cancel := chromedp.NewContext(initialCtx) // chromedp.WithDebugf(log.Printf),
 	return cctx, cancel
 }
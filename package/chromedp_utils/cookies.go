@@ -0,0 +1,233 @@
+package chromedputils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	CookiesJSON     = "json"
+	CookiesNetscape = "netscape"
+)
+
+const netscapeHeader = "# Netscape HTTP Cookie File"
+
+// Cookie is a browser cookie in the format of the cookie export browser
+// extensions, so their files can be imported as is.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expirationDate,omitempty"` // unix seconds, 0 for session cookies
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// GetCookies returns all cookies of the browser.
+func GetCookies(ctx context.Context) ([]*Cookie, error) {
+	var cookies []*Cookie
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		res, err := storage.GetCookies().Do(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range res {
+			cookie := &Cookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				HTTPOnly: c.HTTPOnly,
+				Secure:   c.Secure,
+				SameSite: c.SameSite.String(),
+			}
+			if !c.Session {
+				cookie.Expires = c.Expires
+			}
+			cookies = append(cookies, cookie)
+		}
+		return nil
+	}))
+	return cookies, err
+}
+
+// SetCookies adds cookies to the browser, expired ones are skipped.
+func SetCookies(ctx context.Context, cookies []*Cookie) error {
+	params := []*network.CookieParam{}
+	now := time.Now()
+	for _, c := range cookies {
+		param := &network.CookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+		}
+		if c.Expires > 0 {
+			expires := time.Unix(int64(c.Expires), 0)
+			if expires.Before(now) {
+				continue
+			}
+			t := cdp.TimeSinceEpoch(expires)
+			param.Expires = &t
+		}
+		switch strings.ToLower(c.SameSite) {
+		case "strict":
+			param.SameSite = network.CookieSameSiteStrict
+		case "lax":
+			param.SameSite = network.CookieSameSiteLax
+		case "none", "no_restriction":
+			param.SameSite = network.CookieSameSiteNone
+		}
+		params = append(params, param)
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return storage.SetCookies(params).Do(ctx)
+	}))
+}
+
+// LoadCookies reads cookies from the JSON or Netscape cookies.txt file, the
+// format is detected by the content.
+func LoadCookies(path string) ([]*Cookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var cookies []*Cookie
+		if err := json.Unmarshal(trimmed, &cookies); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return cookies, nil
+	}
+	cookies, err := readNetscape(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cookies, nil
+}
+
+// SaveCookies writes cookies to the file, in the Netscape format for .txt
+// files and in JSON otherwise. The cookies may hold logged in sessions, so
+// the file is readable by the owner only.
+func SaveCookies(path string, cookies []*Cookie) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// an existing file keeps its permissions on open
+	if err := f.Chmod(0o600); err != nil {
+		return err
+	}
+	if CookiesFormat(path) == CookiesNetscape {
+		return writeNetscape(f, cookies)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cookies)
+}
+
+// CookiesFormat returns the format SaveCookies uses for the file.
+func CookiesFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".txt") {
+		return CookiesNetscape
+	}
+	return CookiesJSON
+}
+
+// readNetscape parses the cookies.txt format of curl and wget: tab separated
+// domain, subdomains flag, path, secure, expires, name and value.
+func readNetscape(r io.Reader) ([]*Cookie, error) {
+	cookies := []*Cookie{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(text, "#HttpOnly_") {
+			text = strings.TrimPrefix(text, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab separated fields, got %d", line, len(fields))
+		}
+		expires, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expires %q", line, fields[4])
+		}
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+		cookies = append(cookies, &Cookie{
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Expires:  expires,
+			Name:     fields[5],
+			Value:    fields[6],
+			HTTPOnly: httpOnly,
+		})
+	}
+	return cookies, scanner.Err()
+}
+
+func writeNetscape(w io.Writer, cookies []*Cookie) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, netscapeHeader)
+	fmt.Fprintln(bw)
+	for _, c := range cookies {
+		domain := c.Domain
+		if c.HTTPOnly {
+			domain = "#HttpOnly_" + domain
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain,
+			netscapeBool(strings.HasPrefix(c.Domain, ".")),
+			c.Path,
+			netscapeBool(c.Secure),
+			int64(c.Expires),
+			c.Name,
+			c.Value,
+		)
+	}
+	return bw.Flush()
+}
+
+func netscapeBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
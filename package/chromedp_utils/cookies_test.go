@@ -0,0 +1,73 @@
+package chromedputils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNetscapeRoundTrip(t *testing.T) {
+	cookies := []*Cookie{
+		{Name: "session", Value: "abc", Domain: ".ozon.ru", Path: "/", Expires: 1893456000, HTTPOnly: true, Secure: true},
+		{Name: "x-dest", Value: "-1257786", Domain: "www.wildberries.ru", Path: "/catalog"},
+	}
+	var buf bytes.Buffer
+	if err := writeNetscape(&buf, cookies); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readNetscape(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cookies) {
+		t.Errorf("round trip = %+v, want %+v", got, cookies)
+	}
+}
+
+func TestReadNetscape(t *testing.T) {
+	file := strings.Join([]string{
+		netscapeHeader,
+		"# a comment",
+		"",
+		"#HttpOnly_aliexpress.ru\tTRUE\t/\tTRUE\t1893456000\txman_t\tsecret\r",
+		"wildberries.ru\tFALSE\t/\tFALSE\t0\tlocale\tru",
+	}, "\n")
+	got, err := readNetscape(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Cookie{
+		{Name: "xman_t", Value: "secret", Domain: ".aliexpress.ru", Path: "/", Expires: 1893456000, HTTPOnly: true, Secure: true},
+		{Name: "locale", Value: "ru", Domain: "wildberries.ru", Path: "/"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cookies = %+v, want %+v", got, want)
+	}
+
+	if _, err := readNetscape(strings.NewReader("wildberries.ru\tFALSE\t/\n")); err == nil {
+		t.Error("a line with missing fields is accepted")
+	}
+	if _, err := readNetscape(strings.NewReader("wildberries.ru\tFALSE\t/\tFALSE\tsoon\tlocale\tru\n")); err == nil {
+		t.Error("an invalid expires is accepted")
+	}
+}
+
+func TestSaveCookiesPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCookies(path, []*Cookie{{Name: "session", Value: "abc", Domain: ".ozon.ru", Path: "/"}}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
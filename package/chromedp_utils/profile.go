@@ -0,0 +1,38 @@
+package chromedputils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// DefaultProfilesDir keeps the named profiles when no other directory is set.
+const DefaultProfilesDir = "profiles"
+
+// ProfileDir returns the Chrome user-data-dir of the named profile inside
+// root, creating it on the first use.
+func ProfileDir(root string, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", errors.New("invalid profile name")
+	}
+	if root == "" {
+		root = DefaultProfilesDir
+	}
+	dir, err := filepath.Abs(filepath.Join(root, name))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// WithProfile keeps cookies, local storage and logins of the browser in dir
+// between runs. A profile can be used by a single Chrome at a time.
+func WithProfile(dir string) chromedp.ExecAllocatorOption {
+	return chromedp.UserDataDir(dir)
+}
@@ -0,0 +1,160 @@
+package chromedputils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Reasons why ScrollToEnd stopped.
+const (
+	ScrollEnd           = "end"            // bottom reached and nothing new was loaded
+	ScrollMaxIterations = "max_iterations" // iterations limit reached
+	ScrollTimeout       = "timeout"        // total timeout reached
+	ScrollError         = "error"          // browser error, see the returned error
+)
+
+type ScrollOptions struct {
+	// Selector of the elements loaded by scrolling, used to detect progress.
+	Selector string
+	// Step in pixels, the viewport height if 0.
+	Step          int
+	MaxIterations int
+	Timeout       time.Duration
+	// Quiet is the period without DOM mutations and new network requests
+	// after which the page is considered loaded.
+	Quiet time.Duration
+	// IdleTimeout limits waiting for the quiet period after a single step.
+	IdleTimeout time.Duration
+	// StableRounds is the number of steps at the bottom of the page without
+	// new elements after which the scrolling stops.
+	StableRounds int
+}
+
+func DefaultScrollOptions(selector string) ScrollOptions {
+	return ScrollOptions{
+		Selector:      selector,
+		MaxIterations: 200,
+		Timeout:       3 * time.Minute,
+		Quiet:         300 * time.Millisecond,
+		IdleTimeout:   5 * time.Second,
+		StableRounds:  2,
+	}
+}
+
+type ScrollResult struct {
+	Iterations int
+	Count      int // elements matched by the selector after scrolling
+	Reason     string
+}
+
+func (r ScrollResult) String() string {
+	return fmt.Sprintf("scrolled %d times, %d elements, stopped: %s", r.Iterations, r.Count, r.Reason)
+}
+
+type scrollState struct {
+	Count     int   `json:"count"`
+	Bottom    bool  `json:"bottom"`
+	Quiet     int64 `json:"quiet"` // ms since the last DOM mutation
+	Resources int   `json:"resources"`
+}
+
+const scrollObserverJS = `(() => {
+	if (!window.__scrollState) {
+		window.__scrollState = {last: Date.now()};
+		new MutationObserver(() => { window.__scrollState.last = Date.now(); })
+			.observe(document.body, {childList: true, subtree: true});
+	}
+})()`
+
+// ScrollToEnd scrolls the page down step by step until no new elements are
+// loaded at the bottom, waiting for DOM mutations and network requests to
+// settle after every step. It always terminates: by the end of the list,
+// the iterations limit or the total timeout.
+func ScrollToEnd(ctx context.Context, opts ScrollOptions) (ScrollResult, error) {
+	res := ScrollResult{}
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	selector, err := json.Marshal(opts.Selector)
+	if err != nil {
+		return res, err
+	}
+	stateJS := fmt.Sprintf(`(() => ({
+		count: document.querySelectorAll(%s).length,
+		bottom: window.scrollY + window.innerHeight >= document.documentElement.scrollHeight - 2,
+		quiet: Date.now() - window.__scrollState.last,
+		resources: performance.getEntriesByType("resource").length,
+	}))()`, selector)
+	scrollJS := "window.scrollBy(0, window.innerHeight)"
+	if opts.Step > 0 {
+		scrollJS = fmt.Sprintf("window.scrollBy(0, %d)", opts.Step)
+	}
+
+	fail := func(err error) (ScrollResult, error) {
+		if ctx.Err() == context.DeadlineExceeded {
+			res.Reason = ScrollTimeout
+			return res, nil
+		}
+		res.Reason = ScrollError
+		return res, err
+	}
+
+	state := scrollState{}
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(scrollObserverJS, nil),
+		chromedp.Evaluate(stateJS, &state),
+	); err != nil {
+		return fail(err)
+	}
+	res.Count = state.Count
+
+	stable := 0
+	for res.Iterations < opts.MaxIterations {
+		res.Iterations++
+		if err := chromedp.Run(ctx, chromedp.Evaluate(scrollJS, nil)); err != nil {
+			return fail(err)
+		}
+		if err := waitIdle(ctx, stateJS, opts, &state); err != nil {
+			return fail(err)
+		}
+
+		if state.Count > res.Count {
+			stable = 0
+		} else if state.Bottom {
+			stable++
+		}
+		res.Count = state.Count
+		if stable >= opts.StableRounds {
+			res.Reason = ScrollEnd
+			return res, nil
+		}
+	}
+	res.Reason = ScrollMaxIterations
+	return res, nil
+}
+
+// waitIdle polls the page until there are no DOM mutations and no new
+// network requests for the quiet period or the idle timeout is reached.
+func waitIdle(ctx context.Context, stateJS string, opts ScrollOptions, state *scrollState) error {
+	deadline := time.Now().Add(opts.IdleTimeout)
+	resources := -1
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+		if err := chromedp.Run(ctx, chromedp.Evaluate(stateJS, state)); err != nil {
+			return err
+		}
+		quiet := time.Duration(state.Quiet)*time.Millisecond >= opts.Quiet && state.Resources == resources
+		if quiet || time.Now().After(deadline) {
+			return nil
+		}
+		resources = state.Resources
+	}
+}
@@ -2,15 +2,30 @@ package chromedputils
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/chromedp/chromedp"
 )
 
+type timeoutHookKey struct{}
+
+// WithTimeoutHook returns a context which calls fn every time RunWithTimeOut
+// tasks are interrupted by the timeout.
+func WithTimeoutHook(ctx context.Context, fn func()) context.Context {
+	return context.WithValue(ctx, timeoutHookKey{}, fn)
+}
+
 func RunWithTimeOut(ctx context.Context, timeout time.Duration, tasks chromedp.Tasks) chromedp.ActionFunc {
 	return func(ctx context.Context) error {
 		timeoutContext, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
-		return tasks.Do(timeoutContext)
+		err := tasks.Do(timeoutContext)
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			if fn, ok := ctx.Value(timeoutHookKey{}).(func()); ok {
+				fn()
+			}
+		}
+		return err
 	}
 }
@@ -0,0 +1,147 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Amount is a price parsed from the text shown on the page.
+type Amount struct {
+	Value    float64
+	Currency string // ISO 4217 code, empty when the text has no currency
+}
+
+func (a Amount) String() string {
+	return strconv.FormatFloat(a.Value, 'f', -1, 64)
+}
+
+// currencies maps symbols and codes shown on the marketplaces to ISO codes.
+// Longer markers go first so "US $" is not taken for "$" and "CA$" for "A$".
+var currencies = []struct {
+	marker string
+	code   string
+}{
+	{"US $", "USD"},
+	{"US$", "USD"},
+	{"AU $", "AUD"},
+	{"AU$", "AUD"},
+	{"CA $", "CAD"},
+	{"CA$", "CAD"},
+	{"HK $", "HKD"},
+	{"HK$", "HKD"},
+	{"NZ $", "NZD"},
+	{"NZ$", "NZD"},
+	{"MX $", "MXN"},
+	{"MX$", "MXN"},
+	{"A$", "AUD"},
+	{"C$", "CAD"},
+	{"S$", "SGD"},
+	{"R$", "BRL"},
+	{"руб.", "RUB"},
+	{"руб", "RUB"},
+	{"₽", "RUB"},
+	{"$", "USD"}, // unless prefixed by an unknown country, see prefixedDollar
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"¥", "CNY"},
+	{"₸", "KZT"},
+	{"₴", "UAH"},
+	{"Br", "BYN"},
+	{"zł", "PLN"},
+}
+
+var errNoNumber = errors.New("no number")
+
+// numberPattern matches the first number with its thousands and decimal
+// separators, including non-breaking and thin spaces.
+var numberPattern = regexp.MustCompile(`\d+(?:[ \t\x{00a0}\x{2009}\x{202f}'.,]\d+)*`)
+
+// Parse reads the amount and the currency from texts like "1 234,56 ₽",
+// "US $12.99" or "1.234,56 EUR". Spaces are thousands separators, a single
+// separator followed by one or two digits is the decimal one.
+func Parse(text string) (Amount, error) {
+	amount := Amount{}
+	rest := text
+	for _, c := range currencies {
+		if i := strings.Index(rest, c.marker); i >= 0 {
+			if c.marker != "$" || !prefixedDollar(rest[:i]) {
+				amount.Currency = c.code
+			}
+			rest = rest[:i] + " " + rest[i+len(c.marker):]
+			break
+		}
+	}
+	if amount.Currency == "" {
+		amount.Currency, rest = currencyCode(rest)
+	}
+
+	number := numberPattern.FindString(rest)
+	if number == "" {
+		return amount, fmt.Errorf("%q: %w", text, errNoNumber)
+	}
+	number = strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == '.' || r == ',' {
+			return r
+		}
+		return -1
+	}, number)
+
+	value, err := strconv.ParseFloat(normalize(number), 64)
+	if err != nil {
+		return amount, fmt.Errorf("%q: %w", text, err)
+	}
+	amount.Value = value
+	return amount, nil
+}
+
+// dollarPrefixPattern matches a country prefix like "NT" or "NT " right
+// before "$", the dollar of another country than the listed ones.
+var dollarPrefixPattern = regexp.MustCompile(`(?:^|[^A-Za-z])[A-Z]{1,3} ?$`)
+
+func prefixedDollar(before string) bool {
+	return dollarPrefixPattern.MatchString(before)
+}
+
+// currencyCode finds a three letter upper case currency code in the text.
+func currencyCode(text string) (string, string) {
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(field) == 3 && strings.ToUpper(field) == field && !strings.ContainsFunc(field, func(r rune) bool { return r > unicode.MaxASCII }) {
+			return field, strings.Replace(text, field, " ", 1)
+		}
+	}
+	return "", text
+}
+
+// normalize converts the number with any thousands and decimal separators to
+// the form accepted by strconv.
+func normalize(number string) string {
+	lastDot := strings.LastIndexByte(number, '.')
+	lastComma := strings.LastIndexByte(number, ',')
+	if lastDot >= 0 && lastComma >= 0 {
+		// both are used, the last one separates decimals
+		decimal, thousands := ".", ","
+		if lastComma > lastDot {
+			decimal, thousands = ",", "."
+		}
+		number = strings.ReplaceAll(number, thousands, "")
+		return strings.Replace(number, decimal, ".", 1)
+	}
+
+	sep := "."
+	if lastComma >= 0 {
+		sep = ","
+	}
+	switch strings.Count(number, sep) {
+	case 0:
+		return number
+	case 1:
+		if digits := len(number) - strings.LastIndex(number, sep) - 1; digits <= 2 {
+			return strings.Replace(number, sep, ".", 1)
+		}
+	}
+	return strings.ReplaceAll(number, sep, "")
+}
@@ -0,0 +1,76 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text     string
+		value    float64
+		currency string
+	}{
+		{"1 234,56 ₽", 1234.56, "RUB"},
+		{"1 234 ₽", 1234, "RUB"},
+		{"1 234,5 руб.", 1234.5, "RUB"},
+		{"12 990 руб", 12990, "RUB"},
+		{"US $12.99", 12.99, "USD"},
+		{"US$1,299.00", 1299, "USD"},
+		{"$0.99", 0.99, "USD"},
+		{"от $5", 5, "USD"},
+		{"1.234,56 EUR", 1234.56, "EUR"},
+		{"€1.234", 1234, "EUR"},
+		{"£12", 12, "GBP"},
+		{"C$ 15.50", 15.5, "CAD"},
+		{"CA$15.50", 15.5, "CAD"},
+		{"AU $20", 20, "AUD"},
+		{"A$20", 20, "AUD"},
+		{"HK$99.9", 99.9, "HKD"},
+		{"NT$300", 300, ""},
+		{"1'234.50 CHF", 1234.5, "CHF"},
+		{"5 000 ₸", 5000, "KZT"},
+		{"129", 129, ""},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if got.Value != tt.value || got.Currency != tt.currency {
+			t.Errorf("Parse(%q) = %v %q, want %v %q", tt.text, got.Value, got.Currency, tt.value, tt.currency)
+		}
+	}
+}
+
+func TestParseNoNumber(t *testing.T) {
+	for _, text := range []string{"", "₽", "Нет в наличии"} {
+		if _, err := Parse(text); !errors.Is(err, errNoNumber) {
+			t.Errorf("Parse(%q) error = %v, want no number", text, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"1234", "1234"},
+		{"1234,56", "1234.56"},
+		{"1234.5", "1234.5"},
+		{"1,234", "1234"},
+		{"1.234", "1234"},
+		{"1.234.567", "1234567"},
+		{"1,234,567", "1234567"},
+		{"1.234,56", "1234.56"},
+		{"1,234.56", "1234.56"},
+		{"1.234.567,8", "1234567.8"},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.number); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.number, got, tt.want)
+		}
+	}
+}
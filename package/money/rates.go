@@ -0,0 +1,65 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// Rates converts amounts between currencies. Every rate is the price of one
+// unit of the currency in the base currency.
+type Rates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// LoadRates reads rates from a JSON file like
+// {"base": "RUB", "rates": {"USD": 92.5, "EUR": 100.2}}.
+func LoadRates(path string) (*Rates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rates := &Rates{}
+	if err := json.Unmarshal(data, rates); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if rates.Base == "" {
+		return nil, fmt.Errorf("%s: base currency is required", path)
+	}
+	for code, rate := range rates.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("%s: invalid rate of %s", path, code)
+		}
+	}
+	return rates, nil
+}
+
+func (r *Rates) rate(currency string) (float64, bool) {
+	if strings.EqualFold(currency, r.Base) {
+		return 1, true
+	}
+	rate, ok := r.Rates[strings.ToUpper(currency)]
+	return rate, ok
+}
+
+// Convert returns the amount in the target currency rounded to cents.
+func (r *Rates) Convert(amount Amount, target string) (Amount, error) {
+	if strings.EqualFold(amount.Currency, target) {
+		return amount, nil
+	}
+	from, ok := r.rate(amount.Currency)
+	if !ok {
+		return amount, fmt.Errorf("no rate of %q", amount.Currency)
+	}
+	to, ok := r.rate(target)
+	if !ok {
+		return amount, fmt.Errorf("no rate of %q", target)
+	}
+	return Amount{
+		Value:    math.Round(amount.Value*from/to*100) / 100,
+		Currency: strings.ToUpper(target),
+	}, nil
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type quotaCount struct {
+	Day  string `json:"day"`
+	Used int    `json:"used"`
+}
+
+// quotaFile keeps the requests counted against the daily quota in a JSON file,
+// so the quota holds across runs started by cron or restarts of the server.
+// The file is re-read before every change and is not locked: processes
+// crawling the same marketplace at the same time may lose a few requests.
+type quotaFile struct {
+	mu   sync.Mutex
+	path string
+}
+
+// add changes the marketplace count of the day by delta and returns the new
+// count. The count of another day is reset. A positive delta is rejected
+// with ErrQuotaExceeded once the count reaches the quota.
+func (f *quotaFile) add(marketplace string, day string, delta int, quota int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts := map[string]*quotaCount{}
+	data, err := os.ReadFile(f.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &counts); err != nil {
+			return 0, err
+		}
+	}
+
+	count, ok := counts[marketplace]
+	if !ok || count.Day != day {
+		count = &quotaCount{Day: day}
+		counts[marketplace] = count
+	}
+	if delta > 0 && quota > 0 && count.Used >= quota {
+		return count.Used, ErrQuotaExceeded
+	}
+	count.Used = max(count.Used+delta, 0)
+
+	data, err = json.MarshalIndent(counts, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), os.ModePerm); err != nil {
+		return 0, err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return 0, err
+	}
+	return count.Used, os.Rename(tmp, f.path)
+}
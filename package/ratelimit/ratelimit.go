@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("daily request quota exceeded")
+
+type Config struct {
+	RequestsPerMinute int           // 0 means unlimited
+	MinDelay          time.Duration // random delay between requests
+	MaxDelay          time.Duration
+	DailyQuota        int // 0 means unlimited
+}
+
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		RequestsPerMinute int    `json:"requests_per_minute"`
+		MinDelay          string `json:"min_delay"`
+		MaxDelay          string `json:"max_delay"`
+		DailyQuota        int    `json:"daily_quota"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	c.RequestsPerMinute = raw.RequestsPerMinute
+	c.DailyQuota = raw.DailyQuota
+	for _, d := range []struct {
+		value string
+		dst   *time.Duration
+	}{{raw.MinDelay, &c.MinDelay}, {raw.MaxDelay, &c.MaxDelay}} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return err
+		}
+		*d.dst = v
+	}
+	return nil
+}
+
+// Limiter spaces requests out evenly according to requests per minute, adds
+// a random delay and counts requests against the daily quota. It is safe for
+// concurrent use, so a single limiter is shared by all workers crawling the
+// same marketplace. The daily count is kept in memory of the process unless
+// the registry persists it, see Registry.PersistQuota.
+type Limiter struct {
+	mu          sync.Mutex
+	cfg         Config
+	next        time.Time
+	day         string
+	used        int
+	marketplace string
+	quota       *quotaFile
+}
+
+func New(cfg Config) *Limiter {
+	return &Limiter{cfg: cfg}
+}
+
+// Wait blocks until the next request is allowed. A wait canceled by the
+// context is not counted against the daily quota.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	day := now.Format("2006-01-02")
+	if err := l.count(day, 1); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+
+	at := now
+	if l.next.After(at) {
+		at = l.next
+	}
+	at = at.Add(l.delay())
+	l.next = at
+	if l.cfg.RequestsPerMinute > 0 {
+		l.next = at.Add(time.Minute / time.Duration(l.cfg.RequestsPerMinute))
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.count(day, -1)
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-time.After(time.Until(at)):
+		return nil
+	}
+}
+
+// count changes the number of requests of the day by delta, the caller holds
+// the lock.
+func (l *Limiter) count(day string, delta int) error {
+	if day != l.day {
+		l.day, l.used = day, 0
+	}
+	if l.quota != nil && l.cfg.DailyQuota > 0 {
+		used, err := l.quota.add(l.marketplace, day, delta, l.cfg.DailyQuota)
+		if err != nil {
+			return err
+		}
+		l.used = used
+		return nil
+	}
+	if delta > 0 && l.cfg.DailyQuota > 0 && l.used >= l.cfg.DailyQuota {
+		return ErrQuotaExceeded
+	}
+	l.used = max(l.used+delta, 0)
+	return nil
+}
+
+func (l *Limiter) delay() time.Duration {
+	if l.cfg.MaxDelay <= l.cfg.MinDelay {
+		return l.cfg.MinDelay
+	}
+	return l.cfg.MinDelay + time.Duration(rand.Int63n(int64(l.cfg.MaxDelay-l.cfg.MinDelay)))
+}
+
+// Registry keeps a limiter per marketplace.
+type Registry struct {
+	mu       sync.Mutex
+	configs  map[string]Config
+	limiters map[string]*Limiter
+	quota    *quotaFile
+}
+
+func NewRegistry(configs map[string]Config) *Registry {
+	return &Registry{configs: configs, limiters: map[string]*Limiter{}}
+}
+
+// LoadRegistry reads per-marketplace limits from a JSON file:
+//
+//	{"wb": {"requests_per_minute": 20, "min_delay": "2s", "max_delay": "5s", "daily_quota": 5000}}
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	configs := map[string]Config{}
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+	return NewRegistry(configs), nil
+}
+
+// PersistQuota keeps the daily quota counts in the JSON file at path instead of
+// the process memory, so the quota holds across runs. It must be called
+// before the limiters are used.
+func (r *Registry) PersistQuota(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quota = &quotaFile{path: path}
+}
+
+// Get returns the marketplace limiter or nil if it is not limited.
+func (r *Registry) Get(marketplace string) *Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.limiters[marketplace]; ok {
+		return l
+	}
+	cfg, ok := r.configs[marketplace]
+	if !ok {
+		return nil
+	}
+	l := New(cfg)
+	l.marketplace, l.quota = marketplace, r.quota
+	r.limiters[marketplace] = l
+	return l
+}
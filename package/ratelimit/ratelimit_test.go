@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuotaPersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+	configs := map[string]Config{"wb": {DailyQuota: 2}}
+	ctx := context.Background()
+
+	first := NewRegistry(configs)
+	first.PersistQuota(path)
+	if err := first.Get("wb").Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next run sees the request of the previous one
+	second := NewRegistry(configs)
+	second.PersistQuota(path)
+	l := second.Get("wb")
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Wait(ctx); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("third request error = %v, want ErrQuotaExceeded", err)
+	}
+}
+
+func TestCanceledWaitNotCounted(t *testing.T) {
+	l := New(Config{MinDelay: time.Hour, DailyQuota: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if l.used != 0 {
+		t.Errorf("used = %d after canceled wait, want 0", l.used)
+	}
+}
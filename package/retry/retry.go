@@ -0,0 +1,66 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Policy retries an action with exponential backoff and jitter.
+type Policy struct {
+	Attempts   int           // total attempts including the first one
+	Backoff    time.Duration // pause after the first failed attempt
+	MaxBackoff time.Duration // upper bound of the pause, unlimited if 0
+	Jitter     float64       // random part of the pause, 0.2 means ±20%
+	// Retryable classifies errors, every error except context cancellation
+	// is retried if nil.
+	Retryable func(err error) bool
+}
+
+// Do calls fn until it succeeds, returns a non retryable error or the
+// attempts are over. The last error is returned.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < max(p.Attempts, 1); attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, p.delay(attempt)); err != nil {
+				return err
+			}
+		}
+		if err = fn(ctx); err == nil || !p.retryable(ctx, err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (p Policy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return true
+}
+
+func (p Policy) delay(attempt int) time.Duration {
+	d := p.Backoff << (attempt - 1)
+	if p.MaxBackoff > 0 && (d > p.MaxBackoff || d <= 0) {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return d
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}